@@ -0,0 +1,175 @@
+package kcd
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/alexisvisco/kcd/internal/cache"
+	"github.com/alexisvisco/kcd/internal/decoder"
+	"github.com/alexisvisco/kcd/pkg/hook"
+)
+
+type bindingsKey struct{}
+
+// Binding tells where the value of a field of the input comes from.
+type Binding struct {
+	// Name is the path of the field in the input struct, like the name of a Field of an Endpoint.
+	Name string
+
+	// Source is the tag of the extractor which supplied the value, "default" for the default tag or the format of
+	// the body, for instance "json". It is empty when no value was found for the field.
+	Source string
+
+	// Path is the path used by the extractor, for instance the name of the query parameter.
+	Path string
+
+	// Values are the raw values supplied by the extractor, there are none for the fields of the body.
+	Values []string
+}
+
+// bindings are the bindings of a request, filled when the input is bound.
+type bindings struct {
+	list []Binding
+}
+
+// BindingInfo returns the bindings of the fields of the input of the request, in the order they were bound.
+// It is available to the handlers through their context, and to the hooks through the context of the request.
+// The fields present in the body are listed by their path, nested ones included, when the route tracks the fields
+// of the body, see hook.BodyFields. Else the top level fields of the body with a non zero value are listed.
+func BindingInfo(ctx context.Context) []Binding {
+	b, ok := ctx.Value(bindingsKey{}).(*bindings)
+	if !ok {
+		return nil
+	}
+
+	return append([]Binding(nil), b.list...)
+}
+
+func contextWithBindings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bindingsKey{}, &bindings{})
+}
+
+// addBindings records the bindings in the context of the request.
+func addBindings(r *http.Request, list ...Binding) {
+	if b, ok := r.Context().Value(bindingsKey{}).(*bindings); ok {
+		b.list = append(b.list, list...)
+	}
+}
+
+// decoderBindings converts the bindings of the decoder.
+func decoderBindings(d *decoder.Decoder) []Binding {
+	list := make([]Binding, 0, len(d.Bindings()))
+
+	for _, b := range d.Bindings() {
+		list = append(list, Binding{Name: b.Field, Source: b.Source, Path: b.Path, Values: b.Values})
+	}
+
+	return list
+}
+
+// bodyBindings returns the bindings of the fields of the input, not bound by the extractors, which were set from
+// the body. They are the fields present in the body when its fields are tracked, see hook.BodyFields, else the top
+// level fields with a non zero value.
+func bodyBindings(r *http.Request, c cache.StructCache, input reflect.Value) []Binding {
+	source := bodySource(r.Header.Get("Content-Type"))
+	if source == "" {
+		return nil
+	}
+
+	bound := map[string]bool{}
+	boundFields(c, input.Type(), "", bound)
+
+	list := make([]Binding, 0)
+
+	if fields := hook.BodyFieldsFromContext(r.Context()); fields.Tracked() {
+		for _, path := range fields.Paths() {
+			name, ok := decoder.FieldPath(input.Type(), path)
+			if ok && !bound[name] {
+				list = append(list, Binding{Name: name, Source: source, Path: path})
+			}
+		}
+
+		return list
+	}
+
+	value := input.Elem()
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if bound[field.Name] || !field.IsExported() || value.Field(i).IsZero() {
+			continue
+		}
+
+		path := strings.Split(field.Tag.Get(source), ",")[0]
+		if path == "" {
+			path = field.Name
+		}
+
+		list = append(list, Binding{Name: field.Name, Source: source, Path: path})
+	}
+
+	return list
+}
+
+// boundFields adds the paths of go names of the fields bound by the extractors, and of the structs containing them.
+func boundFields(c cache.StructCache, t reflect.Type, parent string, bound map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for _, metadata := range c.Resolvable {
+		bound[joinPath(parent, t.FieldByIndex(metadata.Index).Name)] = true
+	}
+
+	for _, child := range c.Child {
+		field := t.FieldByIndex(child.Index)
+
+		// the fields of an embedded struct are promoted, like in the bindings of the decoder.
+		path := parent
+		if !field.Anonymous {
+			path = joinPath(parent, field.Name)
+			bound[path] = true
+		}
+
+		boundFields(child, field.Type, path, bound)
+	}
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+// bodySource returns the format of a body of the content type, like "json" for application/problem+json.
+// Forms are bound by their extractors, so they have no format.
+func bodySource(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/x-www-form-urlencoded" || strings.HasPrefix(mediaType, "multipart/") {
+		return ""
+	}
+
+	return mediaType[strings.LastIndexAny(mediaType, "/+")+1:]
+}
+
+// traceBindings sends the bindings of the request to the trace hook, for the verbose mode.
+func traceBindings(r *http.Request, trace hook.TraceHook) {
+	for _, b := range BindingInfo(r.Context()) {
+		if b.Source == "" {
+			trace(r, "kcd: no value bound", map[string]interface{}{"field": b.Name})
+			continue
+		}
+
+		trace(r, "kcd: value bound", map[string]interface{}{
+			"field":  b.Name,
+			"source": b.Source,
+			"path":   b.Path,
+			"values": b.Values,
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package kcd_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alexisvisco/kcd"
+)
+
+type bindingInput struct {
+	ID    string   `path:"id"`
+	Page  int      `query:"page" default:"1"`
+	Tags  []string `query:"tags" exploder:","`
+	Agent string   `header:"User-Agent"`
+	Token string   `header:"X-Token"`
+	Name  string   `json:"name"`
+	Note  string   `json:"note"`
+
+	Filter struct {
+		Since string `query:"since"`
+	}
+
+	Meta struct {
+		Count int    `json:"count"`
+		Label string `json:"label"`
+	} `json:"meta"`
+}
+
+func bindingHandler(ctx context.Context, _ *bindingInput) ([]kcd.Binding, error) {
+	return kcd.BindingInfo(ctx), nil
+}
+
+type BindingEmbedded struct {
+	Sort string `json:"sort" default:"asc"`
+	Size int    `json:"size"`
+}
+
+type bindingEmbeddedInput struct {
+	*BindingEmbedded
+}
+
+func TestBindingInfo(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/{id}", kcd.Handler(bindingHandler, http.StatusOK))
+	r.Post("/embedded", kcd.Handler(func(ctx context.Context, _ *bindingEmbeddedInput) ([]kcd.Binding, error) {
+		return kcd.BindingInfo(ctx), nil
+	}, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should expose the source of each field", func(t *testing.T) {
+		bindings := e.POST("/42").
+			WithQuery("tags", "a,b").
+			WithQuery("since", "yesterday").
+			WithHeader("User-Agent", "kcd").
+			WithJSON(map[string]string{"name": ValString}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array()
+
+		bindings.Equal([]kcd.Binding{
+			{Name: "Name", Source: "json", Path: "name"},
+			{Name: "ID", Source: "path", Path: "id", Values: []string{"42"}},
+			{Name: "Page", Source: "default", Path: "page", Values: []string{"1"}},
+			{Name: "Tags", Source: "query", Path: "tags", Values: []string{"a", "b"}},
+			{Name: "Agent", Source: "header", Path: "User-Agent", Values: []string{"kcd"}},
+			{Name: "Token"},
+			{Name: "Filter.Since", Source: "query", Path: "since", Values: []string{"yesterday"}},
+		})
+	})
+
+	t.Run("it should expose the nested fields and the zero values of the body", func(t *testing.T) {
+		bindings := e.POST("/42").
+			WithJSON(map[string]interface{}{"note": "", "meta": map[string]int{"count": 0}, "page": 2}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array()
+
+		bindings.Equal([]kcd.Binding{
+			{Name: "Meta", Source: "json", Path: "meta"},
+			{Name: "Meta.Count", Source: "json", Path: "meta.count"},
+			{Name: "Note", Source: "json", Path: "note"},
+			{Name: "ID", Source: "path", Path: "id", Values: []string{"42"}},
+			{Name: "Page", Source: "json", Path: "Page"},
+			{Name: "Tags"},
+			{Name: "Agent", Source: "header", Path: "User-Agent", Values: []string{"Go-http-client/1.1"}},
+			{Name: "Token"},
+			{Name: "Filter.Since"},
+		})
+	})
+
+	t.Run("it should expose the promoted fields of an embedded struct once", func(t *testing.T) {
+		e.POST("/embedded").WithJSON(map[string]interface{}{"sort": "desc", "size": 0}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().Equal([]kcd.Binding{
+			{Name: "Size", Source: "json", Path: "size"},
+			{Name: "Sort", Source: "json", Path: "sort"},
+		})
+	})
+
+	t.Run("it should have no bindings outside of a request", func(t *testing.T) {
+		assert.Nil(t, kcd.BindingInfo(context.Background()))
+	})
+}
+
+func TestVerbose(t *testing.T) {
+	hooks := logrus.StandardLogger().ReplaceHooks(logrus.LevelHooks{})
+	defer logrus.StandardLogger().ReplaceHooks(hooks)
+
+	logs := test.NewGlobal()
+
+	r := chi.NewRouter()
+	r.Post("/{id}", kcd.Handler(bindingHandler, http.StatusOK, kcd.WithVerbose(true)))
+	r.Post("/quiet/{id}", kcd.Handler(bindingHandler, http.StatusOK))
+
+	var traces []string
+	r.Post("/custom/{id}", kcd.Handler(bindingHandler, http.StatusOK, kcd.WithVerbose(true),
+		kcd.WithTraceHook(func(_ *http.Request, message string, fields map[string]interface{}) {
+			traces = append(traces, message+" "+fields["field"].(string))
+		})))
+	r.Post("/no-hook/{id}", kcd.Handler(bindingHandler, http.StatusOK, kcd.WithVerbose(true),
+		kcd.WithTraceHook(nil), kcd.WithLogHook(nil)))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should not trace the bindings by default", func(t *testing.T) {
+		logs.Reset()
+
+		e.POST("/quiet/42").Expect().Status(http.StatusOK)
+
+		assert.Empty(t, logs.AllEntries())
+	})
+
+	t.Run("it should trace the bindings in verbose mode", func(t *testing.T) {
+		logs.Reset()
+
+		e.POST("/42").WithQuery("page", "3").Expect().Status(http.StatusOK)
+
+		entries := logs.AllEntries()
+		assert.Len(t, entries, 6)
+
+		assert.Equal(t, "kcd: value bound", entries[1].Message)
+		assert.Equal(t, logrus.InfoLevel, entries[1].Level)
+		assert.Equal(t, "/42", entries[1].Data["request"])
+		assert.Equal(t, http.MethodPost, entries[1].Data["method"])
+		assert.Equal(t, "Page", entries[1].Data["field"])
+		assert.Equal(t, "query", entries[1].Data["source"])
+		assert.Equal(t, "page", entries[1].Data["path"])
+		assert.Equal(t, []string{"3"}, entries[1].Data["values"])
+
+		assert.Equal(t, "kcd: no value bound", entries[2].Message)
+		assert.Equal(t, "Tags", entries[2].Data["field"])
+	})
+
+	t.Run("it should trace the bindings through the trace hook", func(t *testing.T) {
+		logs.Reset()
+
+		e.POST("/custom/42").Expect().Status(http.StatusOK)
+
+		assert.Empty(t, logs.AllEntries())
+		assert.Len(t, traces, 6)
+		assert.Equal(t, "kcd: value bound ID", traces[0])
+	})
+
+	t.Run("it should not trace the bindings without trace hook", func(t *testing.T) {
+		logs.Reset()
+
+		e.POST("/no-hook/42").Expect().Status(http.StatusOK)
+
+		assert.Empty(t, logs.AllEntries())
+	})
+}
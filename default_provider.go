@@ -0,0 +1,77 @@
+package kcd
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alexisvisco/kcd/internal/decoder"
+)
+
+// DefaultProvider computes the value of a default tag starting with "@" for a request, like `default:"@now"`.
+// The argument is the text after the colon of the tag, "REGION" for `default:"@env:REGION"`.
+// The value is converted to the type of the field like a literal default value.
+type DefaultProvider func(ctx context.Context, arg string) (string, error)
+
+// defaultProviders are the providers of the default configuration:
+//   - @now is the current time in the RFC 3339 format
+//   - @uuid is a random UUID (version 4)
+//   - @env:NAME is the value of the environment variable NAME, which must be set
+func defaultProviders() map[string]DefaultProvider {
+	return map[string]DefaultProvider{
+		"now":  provideNow,
+		"uuid": provideUUID,
+		"env":  provideEnv,
+	}
+}
+
+func provideNow(context.Context, string) (string, error) {
+	return time.Now().UTC().Format(time.RFC3339Nano), nil
+}
+
+func provideUUID(context.Context, string) (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+// provideEnv fails if the variable is not set, it is a misconfiguration of the server and not of the request.
+func provideEnv(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("the environment variable %s is not set", name)
+	}
+
+	return value, nil
+}
+
+// provideDefault returns the decoder provider of the default values computed by the providers.
+func provideDefault(providers map[string]DefaultProvider) decoder.DefaultProvider {
+	return func(ctx context.Context, name, arg string) (string, error) {
+		provider, ok := providers[name]
+		if !ok {
+			return "", fmt.Errorf("unknown default provider @%s", name)
+		}
+
+		return provider(ctx, arg)
+	}
+}
+
+// checkDefaultProviders panics if the default tag of a field refers to an unknown provider.
+func (c Configuration) checkDefaultProviders(fields []Field) {
+	for _, field := range fields {
+		if name, _, ok := decoder.ParseDefaultProvider(field.Default); ok {
+			if _, exist := c.DefaultProviders[name]; !exist {
+				panic(fmt.Sprintf("unknown default provider @%s for field %s", name, field.Name))
+			}
+		}
+	}
+}
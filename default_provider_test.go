@@ -0,0 +1,132 @@
+package kcd_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alexisvisco/kcd"
+)
+
+type tenantKey struct{}
+
+type providerInput struct {
+	Since  time.Time `query:"since" default:"@now"`
+	ID     string    `query:"id" default:"@uuid"`
+	Region string    `query:"region" default:"@env:KCD_TEST_REGION"`
+	Limit  int       `query:"limit" default:"@tenantDefaultLimit"`
+	Handle string    `query:"handle" default:"@@kcd"`
+}
+
+type providerInvalidInput struct {
+	Invalid int `query:"invalid" default:"@env:KCD_TEST_INVALID"`
+}
+
+func providerHandler(in *providerInput) (*providerInput, error) {
+	return in, nil
+}
+
+func tenantDefaultLimit(ctx context.Context, _ string) (string, error) {
+	limit, ok := ctx.Value(tenantKey{}).(string)
+	if !ok {
+		return "", errors.New("no tenant")
+	}
+
+	return limit, nil
+}
+
+func TestDefaultProviders(t *testing.T) {
+	t.Setenv("KCD_TEST_REGION", "eu-west-1")
+
+	engine := kcd.New(kcd.WithDefaultProvider("tenantDefaultLimit", tenantDefaultLimit))
+
+	r := chi.NewRouter()
+	r.With(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, "25")))
+		})
+	}).Get("/", engine.Handler(providerHandler, http.StatusOK))
+	r.Get("/no-tenant", engine.Handler(providerHandler, http.StatusOK))
+	r.Get("/no-tenant/ambiguous", engine.Handler(providerHandler, http.StatusOK, kcd.WithRejectAmbiguous(true)))
+	r.Get("/invalid", engine.Handler(func(*providerInvalidInput) error { return nil }, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should compute the defaults with the providers", func(t *testing.T) {
+		before := time.Now()
+
+		json := e.GET("/").Expect().Status(http.StatusOK).JSON().Object()
+
+		since, err := time.Parse(time.RFC3339Nano, json.Value("Since").String().Raw())
+		assert.NoError(t, err)
+		assert.WithinDuration(t, before, since, time.Second)
+
+		json.Value("ID").String().Match("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+		json.ValueEqual("Region", "eu-west-1")
+		json.ValueEqual("Limit", 25)
+		json.ValueEqual("Handle", "@kcd")
+	})
+
+	t.Run("it should compute a new value for each request", func(t *testing.T) {
+		first := e.GET("/").Expect().JSON().Path("$.ID").String().Raw()
+		e.GET("/").Expect().JSON().Path("$.ID").NotEqual(first)
+	})
+
+	t.Run("it should not compute the default of a field with a value", func(t *testing.T) {
+		e.GET("/").WithQuery("limit", 5).WithQuery("region", "us-east-1").Expect().
+			Status(http.StatusOK).
+			JSON().Object().ValueEqual("Limit", 5).ValueEqual("Region", "us-east-1")
+	})
+
+	t.Run("it should convert the computed value like a literal default", func(t *testing.T) {
+		t.Setenv("KCD_TEST_INVALID", "abc")
+
+		e.GET("/invalid").Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields.invalid").Equal("invalid integer")
+	})
+
+	t.Run("it should fail when the environment variable is not set", func(t *testing.T) {
+		e.GET("/invalid").Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Path("$.error_description").Equal(`unable to compute the default value "@env:KCD_TEST_INVALID"`)
+	})
+
+	t.Run("it should fail when a provider fails", func(t *testing.T) {
+		e.GET("/no-tenant").Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Path("$.error_description").Equal(`unable to compute the default value "@tenantDefaultLimit"`)
+	})
+
+	t.Run("it should not compute the default of a field with a value when rejecting the ambiguities", func(t *testing.T) {
+		e.GET("/no-tenant/ambiguous").WithQuery("limit", 3).Expect().
+			Status(http.StatusOK).
+			JSON().Object().ValueEqual("Limit", 3)
+	})
+
+	t.Run("it should panic with an unknown provider", func(t *testing.T) {
+		assert.Panics(t, func() {
+			kcd.Handler(providerHandler, http.StatusOK)
+		})
+
+		assert.Panics(t, func() {
+			kcd.Handle(func(context.Context, *providerInput) (*providerInput, error) { return nil, nil }, http.StatusOK)
+		})
+
+		assert.Panics(t, func() {
+			kcd.HandleHTTP(func(http.ResponseWriter, *http.Request, *providerInput) (*providerInput, error) {
+				return nil, nil
+			}, http.StatusOK)
+		})
+	})
+}
@@ -0,0 +1,143 @@
+package kcd
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/alexisvisco/kcd/internal/cache"
+)
+
+// Endpoint is a kcd handler ready to serve requests.
+// It exposes the metadata of the handler so tools (documentation, linters ...) do not have to parse
+// the struct tags themselves.
+type Endpoint struct {
+	// Name is the name of the handler function.
+	Name string
+
+	// StatusCode is the default status code of the handler.
+	StatusCode int
+
+	// Input is the type of the input struct, nil if the handler has no input.
+	Input reflect.Type
+
+	// Output is the type of the output, nil if the handler has no output or if it is an interface.
+	Output reflect.Type
+
+	// Fields are the fields of the input bound by the extractors, and the required keys of the body.
+	Fields []Field
+
+	handler http.HandlerFunc
+}
+
+// Field is a field of an input bound by the extractors, or a required key of the body without Sources.
+type Field struct {
+	// Name is the path of the field in the input struct, for instance "Pagination.Limit".
+	// Embedded structs are not part of the name since their fields are promoted.
+	Name string
+
+	// Type is the type of the field as declared in the struct.
+	Type reflect.Type
+
+	// Tag is the tag of the field, useful to read tags unknown to kcd.
+	Tag reflect.StructTag
+
+	// Sources are the extractors tags of the field with their path, for instance {"query": "limit"}.
+	Sources map[string]string
+
+	// Default is the value of the default tag.
+	Default string
+
+	// Exploder is the value of the exploder tag.
+	Exploder string
+
+	// Multiple is true when the field accepts multiple values, for a slice or an array.
+	Multiple bool
+
+	// Required is true when the field must be present in the request, from the required option of a tag like
+	// `query:"page,required"` or from the `required:"true"` tag.
+	Required bool
+}
+
+// ServeHTTP implements http.Handler.
+func (e *Endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.handler(w, r)
+}
+
+// newEndpoint returns the endpoint of a handler of the engine, it panics if a field refers to an unknown default
+// provider.
+func (e *Engine) newEndpoint(
+	name string,
+	statusCode int,
+	in, out reflect.Type,
+	cacheStruct cache.StructCache,
+	handler http.HandlerFunc,
+) *Endpoint {
+	endpoint := &Endpoint{
+		Name:       name,
+		StatusCode: statusCode,
+		Input:      in,
+		Output:     out,
+		Fields:     endpointFields(in, cacheStruct, ""),
+		handler:    handler,
+	}
+
+	e.config.checkDefaultProviders(endpoint.Fields)
+
+	return endpoint
+}
+
+// endpointFields flattens the cache of the struct t into a list of fields.
+func endpointFields(t reflect.Type, c cache.StructCache, parent string) []Field {
+	if t == nil {
+		return nil
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	fields := make([]Field, 0, len(c.Resolvable))
+
+	for _, metadata := range c.Resolvable {
+		structField := t.FieldByIndex(metadata.Index)
+
+		sources := make(map[string]string, len(metadata.Paths))
+		for tag, path := range metadata.Paths {
+			if tag != "default" {
+				sources[tag] = path
+			}
+		}
+
+		fields = append(fields, Field{
+			Name:     fieldName(parent, structField.Name),
+			Type:     structField.Type,
+			Tag:      structField.Tag,
+			Sources:  sources,
+			Default:  metadata.DefaultValue,
+			Exploder: metadata.Exploder,
+			Multiple: metadata.ArrayOrSlice,
+			Required: metadata.Required,
+		})
+	}
+
+	for _, child := range c.Child {
+		structField := t.FieldByIndex(child.Index)
+
+		name := parent
+		if !structField.Anonymous {
+			name = fieldName(parent, structField.Name)
+		}
+
+		fields = append(fields, endpointFields(structField.Type, child, name)...)
+	}
+
+	return fields
+}
+
+func fieldName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
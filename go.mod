@@ -1,18 +1,21 @@
 module github.com/alexisvisco/kcd
 
-go 1.18
+go 1.19
 
 require (
 	github.com/alexisvisco/ozzo-validation/v4 v4.3.1
+	github.com/andybalholm/brotli v1.0.1
 	github.com/gavv/httpexpect v2.0.0+incompatible
 	github.com/go-chi/chi v1.5.4
+	github.com/gorilla/mux v1.8.1
+	github.com/julienschmidt/httprouter v1.3.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.5.1
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
 	github.com/ajg/form v1.5.1 // indirect
-	github.com/andybalholm/brotli v1.0.1 // indirect
 	github.com/asaskevich/govalidator v0.0.0-20200108200545-475eaeb16496 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fasthttp-contrib/websocket v0.0.0-20160511215533-1f3b11f56072 // indirect
@@ -39,5 +42,4 @@ require (
 	github.com/yudai/pp v2.0.1+incompatible // indirect
 	golang.org/x/net v0.0.0-20210428140749-89ef3d95e781 // indirect
 	golang.org/x/sys v0.0.0-20210423082822-04245dca01da // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
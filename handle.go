@@ -0,0 +1,137 @@
+package kcd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+
+	"github.com/alexisvisco/kcd/internal/cache"
+)
+
+// Handle returns a http handler from a typed kcd handler.
+//
+// Unlike Handler, the signature of the kcd handler is checked at compile time and the handler is called
+// without reflection. The input and the output follow the same rules as the ones of Handler:
+//
+//	func(ctx context.Context, in *INPUT) (OUTPUT, error)
+//
+// INPUT must be a struct, use struct{} if your handler does not need an input.
+// If OUTPUT is an interface, returning nil will send a blank response.
+//
+// Handle will panic if INPUT is not a struct.
+//
+// The options override the configuration for this handler only.
+//
+// Handle uses the package level Config, see HandleOn to use another configuration.
+func Handle[In, Out any](
+	h func(ctx context.Context, in *In) (Out, error),
+	defaultStatusCode int,
+	options ...Option,
+) http.HandlerFunc {
+	return HandleOn(Default(), h, defaultStatusCode, options...)
+}
+
+// HandleOn is the same as Handle but uses the configuration of the engine e.
+func HandleOn[In, Out any](
+	e *Engine,
+	h func(ctx context.Context, in *In) (Out, error),
+	defaultStatusCode int,
+	options ...Option,
+) http.HandlerFunc {
+	return TypedEndpoint(e, h, defaultStatusCode, options...).ServeHTTP
+}
+
+// TypedEndpoint is the same as HandleOn but returns an Endpoint which exposes the metadata of the handler.
+func TypedEndpoint[In, Out any](
+	e *Engine,
+	h func(ctx context.Context, in *In) (Out, error),
+	defaultStatusCode int,
+	options ...Option,
+) *Endpoint {
+	return handle(
+		e.with(options...),
+		funcName(h),
+		func(_ http.ResponseWriter, r *http.Request, in *In) (Out, error) { return h(r.Context(), in) },
+		defaultStatusCode,
+	)
+}
+
+// HandleHTTP is the same as Handle but the kcd handler receives the response writer and the request
+// instead of the context.
+//
+//	func(w http.ResponseWriter, r *http.Request, in *INPUT) (OUTPUT, error)
+func HandleHTTP[In, Out any](
+	h func(w http.ResponseWriter, r *http.Request, in *In) (Out, error),
+	defaultStatusCode int,
+	options ...Option,
+) http.HandlerFunc {
+	return HandleHTTPOn(Default(), h, defaultStatusCode, options...)
+}
+
+// HandleHTTPOn is the same as HandleHTTP but uses the configuration of the engine e.
+func HandleHTTPOn[In, Out any](
+	e *Engine,
+	h func(w http.ResponseWriter, r *http.Request, in *In) (Out, error),
+	defaultStatusCode int,
+	options ...Option,
+) http.HandlerFunc {
+	return TypedEndpointHTTP(e, h, defaultStatusCode, options...).ServeHTTP
+}
+
+// TypedEndpointHTTP is the same as HandleHTTPOn but returns an Endpoint which exposes the metadata of the handler.
+func TypedEndpointHTTP[In, Out any](
+	e *Engine,
+	h func(w http.ResponseWriter, r *http.Request, in *In) (Out, error),
+	defaultStatusCode int,
+	options ...Option,
+) *Endpoint {
+	return handle(e.with(options...), funcName(h), h, defaultStatusCode)
+}
+
+func handle[In, Out any](
+	e *Engine,
+	name string,
+	h func(w http.ResponseWriter, r *http.Request, in *In) (Out, error),
+	defaultStatusCode int,
+) *Endpoint {
+	in := reflect.TypeOf((*In)(nil)).Elem()
+	if in.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("invalid input type for handler %s, expected struct, got %v", name, in))
+	}
+
+	cacheStruct := cache.NewStructAnalyzer(e.config.stringsTags(), e.config.valuesTags(), in).Cache()
+	trackBody := e.config.tracksBody(cacheStruct)
+
+	out := reflect.TypeOf((*Out)(nil)).Elem()
+	switch out.Kind() {
+	case reflect.Ptr:
+		out = out.Elem()
+	case reflect.Interface:
+		out = nil
+	}
+
+	httpHandler := func(w http.ResponseWriter, r *http.Request) {
+		e, trackBody := e.serving(cacheStruct, trackBody)
+		r, cancel := e.request(r, trackBody)
+		defer cancel()
+
+		input := new(In)
+
+		if err := e.bind(w, r, cacheStruct, reflect.ValueOf(input)); err != nil {
+			e.config.ErrorHook(w, r, err, e.config.LogHook)
+			return
+		}
+
+		output, err := h(w, r, input)
+
+		e.render(w, r, output, err, defaultStatusCode)
+	}
+
+	return e.newEndpoint(name, defaultStatusCode, in, out, cacheStruct, httpHandler)
+}
+
+func funcName(h interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+}
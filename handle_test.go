@@ -0,0 +1,108 @@
+package kcd_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+type handleInput struct {
+	ID     int    `path:"id"`
+	Name   string `json:"name"`
+	Filter string `query:"filter" default:"all"`
+}
+
+type handleOutput struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+}
+
+func handleTyped(_ context.Context, in *handleInput) (handleOutput, error) {
+	if in.ID == 0 {
+		return handleOutput{}, errors.NewWithKind(errors.KindNotFound, "not found")
+	}
+
+	return handleOutput{ID: in.ID, Name: in.Name, Filter: in.Filter}, nil
+}
+
+func handleTypedHTTP(w http.ResponseWriter, r *http.Request, in *handleInput) (*handleOutput, error) {
+	w.Header().Set("X-Method", r.Method)
+
+	return &handleOutput{ID: in.ID, Name: in.Name, Filter: in.Filter}, nil
+}
+
+func handleTypedEmpty(_ context.Context, _ *struct{}) (interface{}, error) {
+	return nil, nil
+}
+
+func handleTypedStop(_ context.Context, _ *struct{}) (interface{}, error) {
+	return nil, kcd.ErrStopHandler
+}
+
+func TestHandle(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/typed/{id}", kcd.Handle(handleTyped, http.StatusCreated))
+	r.Post("/http/{id}", kcd.HandleHTTP(handleTypedHTTP, http.StatusOK))
+	r.Get("/empty", kcd.Handle(handleTypedEmpty, http.StatusNoContent))
+	r.Get("/stop", kcd.Handle(handleTypedStop, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should bind and render", func(t *testing.T) {
+		j := e.POST("/typed/4").WithJSON(map[string]string{"name": ValString}).Expect().
+			Status(http.StatusCreated).
+			JSON()
+
+		j.Path("$.id").Equal(4)
+		j.Path("$.name").Equal(ValString)
+		j.Path("$.filter").Equal("all")
+	})
+
+	t.Run("it should use the error hook on binding error", func(t *testing.T) {
+		e.POST("/typed/abc").Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields.id").Equal("invalid integer")
+	})
+
+	t.Run("it should use the error hook on handler error", func(t *testing.T) {
+		e.POST("/typed/0").Expect().
+			Status(http.StatusNotFound).
+			JSON().Path("$.error_description").Equal("not found")
+	})
+
+	t.Run("it should give access to the response writer and the request", func(t *testing.T) {
+		expect := e.POST("/http/2").WithQuery("filter", "some").Expect().
+			Status(http.StatusOK)
+
+		expect.Header("X-Method").Equal(http.MethodPost)
+		expect.JSON().Path("$.filter").Equal("some")
+	})
+
+	t.Run("it should render a blank response with a nil output", func(t *testing.T) {
+		e.GET("/empty").Expect().
+			Status(http.StatusNoContent).Body().Equal("")
+	})
+
+	t.Run("it should stop the handler", func(t *testing.T) {
+		body := e.GET("/stop").Expect().Status(http.StatusOK).Body().Raw()
+		assert.Equal(t, "", body)
+	})
+}
+
+func TestHandlePanic(t *testing.T) {
+	assert.Panics(t, func() {
+		kcd.Handle(func(_ context.Context, _ *string) (interface{}, error) { return nil, nil }, http.StatusOK)
+	})
+}
@@ -2,12 +2,14 @@ package kcd
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
 	"net/http"
 	"reflect"
-	"runtime"
 
 	"github.com/alexisvisco/kcd/pkg/errors"
+	"github.com/alexisvisco/kcd/pkg/hook"
+	validation "github.com/alexisvisco/ozzo-validation/v4"
 
 	"github.com/alexisvisco/kcd/internal/cache"
 	"github.com/alexisvisco/kcd/internal/decoder"
@@ -61,7 +63,25 @@ var ErrStopHandler = errors.New("KCD_STOP_HANDLER")
 //
 // Handler will panic if the kcd handler or its input/output values
 // are of incompatible type.
-func Handler(h interface{}, defaultStatusCode int) http.HandlerFunc {
+//
+// The options override the configuration for this handler only, for instance:
+//
+//	kcd.Handler(upload, http.StatusCreated, kcd.WithMaxBodyBytes(50<<20), kcd.WithTimeout(time.Minute))
+//
+// Handler uses the package level Config, see Engine.Handler to use another configuration.
+func Handler(h interface{}, defaultStatusCode int, options ...Option) http.HandlerFunc {
+	return Default().Handler(h, defaultStatusCode, options...)
+}
+
+// Handler returns a http handler using the configuration of the engine, see the package level Handler.
+func (e *Engine) Handler(h interface{}, defaultStatusCode int, options ...Option) http.HandlerFunc {
+	return e.Endpoint(h, defaultStatusCode, options...).ServeHTTP
+}
+
+// Endpoint is the same as Handler but returns an Endpoint which exposes the metadata of the handler.
+func (e *Engine) Endpoint(h interface{}, defaultStatusCode int, options ...Option) *Endpoint {
+	e = e.with(options...)
+
 	hv := reflect.ValueOf(h)
 
 	if hv.Kind() != reflect.Func {
@@ -69,55 +89,43 @@ func Handler(h interface{}, defaultStatusCode int) http.HandlerFunc {
 	}
 	ht := hv.Type()
 
-	funcName := runtime.FuncForPC(hv.Pointer()).Name()
+	name := funcName(h)
 
-	orderInput, in := input(ht, funcName)
+	orderInput, in := input(ht, name)
 
 	// check number of outputs because the std http handler don't return anything but kcd can have a func(res, req) error
 	// so by adding this condition we ensure this is a std http handler.
 	isStdHTTPHandler := isStandardHTTPHandlerInput(orderInput) && ht.NumOut() == 0
 
-	outType := output(ht, funcName, isStdHTTPHandler)
+	outType := output(ht, name, isStdHTTPHandler)
 
-	cacheStruct := cache.NewStructAnalyzer(Config.stringsTags(), Config.valuesTags(), in).Cache()
-
-	var input reflect.Value
+	cacheStruct := cache.NewStructAnalyzer(e.config.stringsTags(), e.config.valuesTags(), in).Cache()
+	trackBody := e.config.tracksBody(cacheStruct)
 
 	// Wrap http handler.
 	httpHandler := func(w http.ResponseWriter, r *http.Request) {
-		// kcd handler has custom input, handle binding.
+		e, trackBody := e.serving(cacheStruct, trackBody)
+		r, cancel := e.request(r, trackBody)
+		defer cancel()
 
-		if in != nil {
-			inputStruct := reflect.New(in)
-			input = inputStruct
-
-			// Bind body
-			if err := Config.BindHook(w, r, input.Interface()); err != nil {
-				Config.ErrorHook(w, r, err, Config.LogHook)
-				return
-			}
-
-			err := decoder.NewDecoder(r, w, Config.StringsExtractors, Config.ValueExtractors).
-				Decode(cacheStruct, input)
+		// input is scoped to the request since the handler is shared between concurrent requests.
+		var input reflect.Value
 
-			if err != nil {
-				Config.ErrorHook(w, r, err, Config.LogHook)
-				return
-			}
+		// kcd handler has custom input, handle binding.
+		if in != nil {
+			input = reflect.New(in)
 
-			if err := Config.ValidateHook(r.Context(), inputStruct.Interface()); err != nil {
-				Config.ErrorHook(w, r, err, Config.LogHook)
+			if err := e.bind(w, r, cacheStruct, input); err != nil {
+				e.config.ErrorHook(w, r, err, e.config.LogHook)
 				return
 			}
 		}
 
 		var (
 			outputStruct interface{}
-			err          interface{}
+			err          error
 		)
 
-		err = nil
-
 		// funcIn contains the input parameters of the kcd handler call.
 		var args []reflect.Value
 		for _, t := range orderInput {
@@ -135,37 +143,170 @@ func Handler(h interface{}, defaultStatusCode int) http.HandlerFunc {
 
 		ret := hv.Call(args)
 
-		if !isStdHTTPHandler {
-			errIndex := 0
-			if outType != nil {
-				outputStruct = ret[0].Interface()
-				errIndex = 1
-			}
+		// the handler must stop because its a std http handler
+		if isStdHTTPHandler {
+			return
+		}
 
-			if !ret[errIndex].IsNil() {
-				err = ret[errIndex].Interface()
-			}
+		errIndex := 0
+		if outType != nil {
+			outputStruct = ret[0].Interface()
+			errIndex = 1
 		}
 
-		// the handler must stop because its a special error or std http handler
-		if err == ErrStopHandler || isStdHTTPHandler {
-			return
+		if !ret[errIndex].IsNil() {
+			err = ret[errIndex].Interface().(error)
 		}
 
-		// Handle the error returned by the handler invocation, if any.
-		if err != nil {
-			Config.ErrorHook(w, r, err.(error), Config.LogHook)
-			return
+		e.render(w, r, outputStruct, err, defaultStatusCode)
+	}
+
+	return e.newEndpoint(name, defaultStatusCode, in, outType, cacheStruct, httpHandler)
+}
+
+// serving returns the engine serving a request and whether it tracks the body, see Configuration.tracksBody.
+// A global engine reads the package level Config again with its options, see Default.
+func (e *Engine) serving(cacheStruct cache.StructCache, trackBody bool) (*Engine, bool) {
+	if !e.global {
+		return e, trackBody
+	}
+
+	current := (&Engine{config: Config}).with(e.options...)
+
+	return current, current.config.tracksBody(cacheStruct)
+}
+
+// request returns the request with the route options and the timeout of the engine.
+// The fields present in the body are recorded if trackBody is true, see Configuration.tracksBody.
+func (e *Engine) request(r *http.Request, trackBody bool) (*http.Request, context.CancelFunc) {
+	ctx := contextWithBindings(hook.ContextWithRouteOptions(r.Context(), e.config.routeOptions()))
+	if trackBody {
+		ctx = hook.ContextWithBodyFields(ctx)
+	}
+
+	cancel := context.CancelFunc(func() {})
+
+	if e.config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.config.Timeout)
+	}
+
+	r = r.WithContext(ctx)
+
+	return r, func() {
+		cancel()
+
+		// the server only removes the temporary files of the multipart form parsed on its own request.
+		if r.MultipartForm != nil {
+			_ = r.MultipartForm.RemoveAll()
 		}
+	}
+}
 
-		// Render the response.
-		if err := Config.RenderHook(w, r, outputStruct, defaultStatusCode); err != nil {
-			Config.ErrorHook(w, r, err, Config.LogHook)
-			return
+// tracksBody returns true if the route needs the fields present in the body, see hook.BodyFields.
+// They tell if a field comes from the body for the default and required tags, the json source and the rejection
+// of ambiguous fields, which fields of the body are unknown in strict mode, and which ones are traced in verbose mode.
+func (c Configuration) tracksBody(cacheStruct cache.StructCache) bool {
+	if c.Strict || c.RejectAmbiguous || c.Verbose || hasBodySource(c.SourceOrder) {
+		return true
+	}
+
+	return cacheStruct.Any(func(metadata cache.FieldMetadata) bool {
+		return metadata.DefaultValue != "" || metadata.Required || hasBodySource(metadata.From)
+	})
+}
+
+func hasBodySource(sources []string) bool {
+	for _, source := range sources {
+		if source == "json" || source == "body" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bind fills the input with the bind hook, the decoder and validate it with the validate hook.
+// The validation errors are merged with the errors of the fields which failed to decode.
+// The bindings of the fields are recorded in the context of the request, see BindingInfo.
+func (e *Engine) bind(
+	w http.ResponseWriter,
+	r *http.Request,
+	cacheStruct cache.StructCache,
+	input reflect.Value,
+) error {
+	if e.config.Verbose && e.config.TraceHook != nil {
+		defer traceBindings(r, e.config.TraceHook)
+	}
+
+	if err := e.config.BindHook(w, r, input.Interface()); err != nil {
+		return err
+	}
+
+	addBindings(r, bodyBindings(r, cacheStruct, input)...)
+
+	d := decoder.NewDecoder(r, w, e.config.StringsExtractors, e.config.ValueExtractors, decoder.Options{
+		SourceOrder:     e.config.SourceOrder,
+		RejectAmbiguous: e.config.RejectAmbiguous,
+		DefaultProvider: provideDefault(e.config.DefaultProviders),
+		Body:            hook.BodyFieldsFromContext(r.Context()),
+	})
+
+	var unknownErr error
+	if e.config.Strict {
+		unknownErr = d.CheckUnknownParameters(cacheStruct, input.Type())
+	}
+
+	decodeErr := d.Decode(cacheStruct, input)
+	addBindings(r, decoderBindings(d)...)
+	if decodeErr != nil {
+		if _, ok := decoder.FieldErrors(decodeErr); !ok {
+			return decodeErr
 		}
 	}
 
-	return httpHandler
+	// the unknown parameters are reported with the errors of the fields.
+	decodeErr = decoder.Join(unknownErr, decodeErr)
+	if decodeErr == nil {
+		return e.config.ValidateHook(r.Context(), input.Interface())
+	}
+
+	// the input is validated even if some fields failed to decode, so the client gets all the errors at once.
+	// The decoding error of a field wins over its validation error.
+	validationErrs, ok := e.config.ValidateHook(r.Context(), input.Interface()).(validation.Errors)
+	if !ok {
+		return decodeErr
+	}
+
+	fields := make(map[string]string, len(validationErrs))
+	for key, err := range validationErrs {
+		fields[key] = err.Error()
+	}
+
+	return decoder.MergeFieldErrors(decodeErr, fields)
+}
+
+// render sends the output of a kcd handler with the render hook, or the error with the error hook.
+func (e *Engine) render(w http.ResponseWriter, r *http.Request, output interface{}, err error, defaultStatusCode int) {
+	// the handler must stop because its a special error
+	if err == ErrStopHandler {
+		return
+	}
+
+	if e.config.Timeout > 0 && goerrors.Is(err, context.DeadlineExceeded) {
+		err = errors.Wrap(err, "handler timed out").WithKind(errors.KindDeadlineExceeded)
+	}
+
+	// Handle the error returned by the handler invocation, if any.
+	if err != nil {
+		e.config.ErrorHook(w, r, err, e.config.LogHook)
+		return
+	}
+
+	// Render the response.
+	if err := e.config.RenderHook(w, r, output, defaultStatusCode); err != nil {
+		e.config.ErrorHook(w, r, err, e.config.LogHook)
+		return
+	}
 }
 
 var interfaceResponseWriter = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
@@ -0,0 +1,135 @@
+package kcd_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alexisvisco/kcd"
+)
+
+const (
+	concurrencyWorkers  = 16
+	concurrencyRequests = 200
+)
+
+type concurrencyInput struct {
+	Path   int    `path:"id"`
+	Query  string `query:"query"`
+	List   []int  `query:"list" exploder:","`
+	Header string `header:"X-Value"`
+	Ctx    string `ctx:"value"`
+	Body   string `json:"body"`
+	Nested struct {
+		Query string `query:"nested"`
+	}
+}
+
+type concurrencyOutput struct {
+	Path   int
+	Query  string
+	List   []int
+	Header string
+	Ctx    string
+	Body   string
+	Nested string
+}
+
+func concurrencyHandler(in *concurrencyInput) (concurrencyOutput, error) {
+	return concurrencyOutput{
+		Path:   in.Path,
+		Query:  in.Query,
+		List:   in.List,
+		Header: in.Header,
+		Ctx:    in.Ctx,
+		Body:   in.Body,
+		Nested: in.Nested.Query,
+	}, nil
+}
+
+func concurrencyTypedHandler(_ context.Context, in *concurrencyInput) (concurrencyOutput, error) {
+	return concurrencyHandler(in)
+}
+
+func TestHandlerConcurrency(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// nolint
+			ctx := context.WithValue(r.Context(), "value", r.Header.Get("X-Ctx"))
+			handler.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+
+	r.Post("/reflect/{id}", kcd.Handler(concurrencyHandler, http.StatusOK))
+	r.Post("/typed/{id}", kcd.Handle(concurrencyTypedHandler, http.StatusOK))
+
+	for _, prefix := range []string{"/reflect", "/typed"} {
+		prefix := prefix
+
+		t.Run(prefix, func(t *testing.T) {
+			var wg sync.WaitGroup
+
+			for worker := 0; worker < concurrencyWorkers; worker++ {
+				wg.Add(1)
+
+				go func(worker int) {
+					defer wg.Done()
+
+					for i := 0; i < concurrencyRequests; i++ {
+						id := worker*concurrencyRequests + i
+						assertConcurrentRequest(t, r, prefix, id)
+					}
+				}(worker)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+// assertConcurrentRequest is called from multiple goroutines so it must not use t.FailNow.
+func assertConcurrentRequest(t *testing.T, h http.Handler, prefix string, id int) {
+	expected := concurrencyOutput{
+		Path:   id,
+		Query:  fmt.Sprintf("query-%d", id),
+		List:   []int{id, id + 1},
+		Header: fmt.Sprintf("header-%d", id),
+		Ctx:    fmt.Sprintf("ctx-%d", id),
+		Body:   fmt.Sprintf("body-%d", id),
+		Nested: fmt.Sprintf("nested-%d", id),
+	}
+
+	body, err := json.Marshal(map[string]string{"body": expected.Body})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	url := fmt.Sprintf("%s/%d?query=%s&list=%d,%d&nested=%s",
+		prefix, id, expected.Query, id, id+1, expected.Nested)
+
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Value", expected.Header)
+	req.Header.Set("X-Ctx", expected.Ctx)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if !assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String()) {
+		return
+	}
+
+	var actual concurrencyOutput
+	if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actual)) {
+		assert.Equal(t, expected, actual)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build go1.22
+
+// the patterns of the ServeMux depend on the go version of the main module, which is older than Go 1.22.
+//go:debug httpmuxgo121=0
+
+package kcd_test
+
+import (
+	"net/http"
+
+	"github.com/alexisvisco/kcd/pkg/extractor"
+)
+
+func init() {
+	testRouters = append(testRouters, testRouter{
+		name:      "net/http",
+		path:      extractor.ServeMuxPath{},
+		parameter: func(name string) string { return "{" + name + "}" },
+		routes: func(routes ...testRoute) http.Handler {
+			r := http.NewServeMux()
+			for _, route := range routes {
+				r.Handle(route.method+" "+route.pattern, route.handler)
+			}
+
+			return r
+		},
+	})
+}
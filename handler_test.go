@@ -8,12 +8,16 @@ import (
 	"time"
 
 	"github.com/alexisvisco/kcd/pkg/errors"
+	validation "github.com/alexisvisco/ozzo-validation/v4"
 
 	"github.com/gavv/httpexpect"
 	"github.com/go-chi/chi"
+	"github.com/gorilla/mux"
+	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/extractor"
 )
 
 const (
@@ -167,13 +171,84 @@ func normalHandler(in *NormalInput) (*NormalOutput, error) {
 	}, nil
 }
 
+// testRoute is a route registered on the routers of the tests.
+type testRoute struct {
+	method, pattern string
+	handler         http.HandlerFunc
+}
+
+// testRouter is a router with its path extractor.
+type testRouter struct {
+	name string
+	path extractor.Strings
+
+	// parameter returns the syntax of a path parameter in a pattern.
+	parameter func(name string) string
+	// routes returns a router serving the routes.
+	routes func(routes ...testRoute) http.Handler
+}
+
+var testRouters = []testRouter{
+	{
+		name:      "chi",
+		path:      extractor.Path{},
+		parameter: func(name string) string { return "{" + name + "}" },
+		routes: func(routes ...testRoute) http.Handler {
+			r := chi.NewRouter()
+			for _, route := range routes {
+				r.Method(route.method, route.pattern, route.handler)
+			}
+
+			return r
+		},
+	},
+	{
+		name:      "gorilla/mux",
+		path:      extractor.MuxPath{},
+		parameter: func(name string) string { return "{" + name + "}" },
+		routes: func(routes ...testRoute) http.Handler {
+			r := mux.NewRouter()
+			for _, route := range routes {
+				r.Handle(route.pattern, route.handler).Methods(route.method)
+			}
+
+			return r
+		},
+	},
+	{
+		name:      "httprouter",
+		path:      extractor.HTTPRouterPath{},
+		parameter: func(name string) string { return ":" + name },
+		routes: func(routes ...testRoute) http.Handler {
+			r := httprouter.New()
+			for _, route := range routes {
+				r.Handler(route.method, route.pattern, route.handler)
+			}
+
+			return r
+		},
+	},
+}
+
 func TestBind(t *testing.T) {
-	r := chi.NewRouter()
+	for _, router := range testRouters {
+		router := router
 
-	r.Get("/hello", kcd.Handler(std, 200))
-	r.Get("/testerrimpl", kcd.Handler(testCustomErrNil, 200))
-	r.Get("/normalhandler", kcd.Handler(normalHandler, 200))
-	r.Post("/{uint}", kcd.Handler(hookBindHandler, 200))
+		t.Run(router.name, func(t *testing.T) {
+			testBind(t, router)
+		})
+	}
+}
+
+func testBind(t *testing.T, router testRouter) {
+	engine := kcd.New(kcd.WithPathExtractor(router.path))
+
+	r := router.routes(
+		testRoute{http.MethodGet, "/hello", engine.Handler(std, 200)},
+		testRoute{http.MethodGet, "/testerrimpl", engine.Handler(testCustomErrNil, 200)},
+		testRoute{http.MethodGet, "/normalhandler", engine.Handler(normalHandler, 200)},
+		testRoute{http.MethodPost, "/" + router.parameter("uint"), engine.Handler(hookBindHandler, 200)},
+	)
 
 	server := httptest.NewServer(r)
 	defer server.Close()
@@ -204,7 +279,8 @@ func TestBind(t *testing.T) {
 
 	t.Run("it should succeed", func(t *testing.T) {
 		expect := e.POST("/4").
-			WithJSON(hookBindStruct{JSONName: ValString}).
+			// the encoding of the whole struct would have a "Default" key, which is not replaced by its default.
+			WithJSON(map[string]string{"name": ValString}).
 			WithQuery("query_string", "query_string").
 			WithQuery("query_float", 1.4).
 			WithQuery("query_bool", true).
@@ -315,3 +391,269 @@ func TestBind(t *testing.T) {
 			JSON().Path("$.fields.slice_int").Equal("invalid integer")
 	})
 }
+
+type bindErrorsInput struct {
+	Page  int    `query:"page" json:"page"`
+	Limit int    `query:"limit" json:"limit"`
+	Count int    `header:"X-Count" json:"count"`
+	Name  string `query:"name" json:"name"`
+
+	Filter struct {
+		Since time.Duration `query:"since"`
+	}
+}
+
+func (in bindErrorsInput) Validate() error {
+	return validation.ValidateStruct(&in,
+		validation.Field(&in.Page, validation.Required, validation.Min(1)),
+		validation.Field(&in.Name, validation.Required),
+	)
+}
+
+func bindErrorsHandler(in *bindErrorsInput) (*bindErrorsInput, error) {
+	return in, nil
+}
+
+func TestBindErrors(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/", kcd.Handler(bindErrorsHandler, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should report all the fields which failed to decode", func(t *testing.T) {
+		json := e.GET("/").
+			WithQuery("page", "abc").
+			WithQuery("limit", "xyz").
+			WithQuery("since", "yes").
+			WithQuery("name", ValString).
+			WithHeader("X-Count", "many").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON()
+
+		json.Path("$.error_description").Equal("the request has one or multiple invalid fields")
+		json.Path("$.fields").Equal(map[string]string{
+			"page":    "invalid integer",
+			"limit":   "invalid integer",
+			"X-Count": "invalid integer",
+			"since":   "unable to parse duration (format: 1ms, 1s, 3h3s)",
+		})
+	})
+
+	t.Run("it should merge the validation errors", func(t *testing.T) {
+		e.GET("/").WithQuery("page", "abc").Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Equal(map[string]string{
+			"page": "invalid integer",
+			"name": "cannot be blank",
+		})
+	})
+
+	t.Run("it should keep the error of a single field", func(t *testing.T) {
+		json := e.GET("/").WithQuery("page", "abc").WithQuery("name", ValString).Expect().
+			Status(http.StatusBadRequest).
+			JSON()
+
+		json.Path("$.error_description").Equal("Bad Request")
+		json.Path("$.fields").Equal(map[string]string{"page": "invalid integer"})
+	})
+
+	t.Run("it should validate a decoded input", func(t *testing.T) {
+		e.GET("/").WithQuery("page", "-1").Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Equal(map[string]string{
+			"page": "must be no less than 1",
+			"name": "cannot be blank",
+		})
+	})
+}
+
+type unmarshallerValueInput struct {
+	Since time.Time                  `query:"since"`
+	Text  StructWithTextUnmarshaller `query:"text"`
+}
+
+func TestUnmarshallerValue(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/", kcd.Handler(func(in *unmarshallerValueInput) (*unmarshallerValueInput, error) {
+		return in, nil
+	}, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should set a field which is not a pointer with its unmarshaller", func(t *testing.T) {
+		json := e.GET("/").WithQuery("since", "2021-03-04T05:06:07Z").WithQuery("text", "kcd").Expect().
+			Status(http.StatusOK).
+			JSON()
+
+		json.Path("$.Since").Equal("2021-03-04T05:06:07Z")
+		json.Path("$.Text.Value").Equal("KCD")
+	})
+}
+
+type defaultInput struct {
+	Name  string `json:"name" default:"anonymous"`
+	Count int    `json:"count" default:"10"`
+	Page  int    `query:"page" json:"page" default:"1"`
+
+	Filter struct {
+		Sort  string `json:"sort" default:"asc"`
+		Since string `query:"since" json:"since" default:"today"`
+	} `json:"filter"`
+
+	Settings *struct {
+		Theme string `json:"theme" default:"light"`
+	} `json:"settings"`
+}
+
+func defaultHandler(in *defaultInput) (*defaultInput, error) {
+	return in, nil
+}
+
+func TestDefault(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/", kcd.Handler(defaultHandler, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should set the defaults without body", func(t *testing.T) {
+		json := e.POST("/").Expect().Status(http.StatusOK).JSON()
+
+		json.Path("$.name").Equal("anonymous")
+		json.Path("$.count").Equal(10)
+		json.Path("$.page").Equal(1)
+		json.Path("$.filter").Equal(map[string]string{"sort": "asc", "since": "today"})
+		json.Path("$.settings.theme").Equal("light")
+	})
+
+	t.Run("it should keep the values of the body", func(t *testing.T) {
+		json := e.POST("/").WithJSON(map[string]interface{}{"name": "kcd", "count": 0, "page": 3}).Expect().
+			Status(http.StatusOK).
+			JSON()
+
+		json.Path("$.name").Equal("kcd")
+		json.Path("$.count").Equal(0)
+		json.Path("$.page").Equal(3)
+	})
+
+	t.Run("it should compare the keys of the body without case", func(t *testing.T) {
+		e.POST("/").WithJSON(map[string]interface{}{"NAME": ""}).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal("")
+	})
+
+	t.Run("it should prefer the query to the body and the default", func(t *testing.T) {
+		e.POST("/").WithQuery("page", 5).WithJSON(map[string]interface{}{"page": 3}).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.page").Equal(5)
+
+		e.POST("/").WithQuery("page", 5).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.page").Equal(5)
+	})
+
+	t.Run("it should keep the values of the nested structs of the body", func(t *testing.T) {
+		json := e.POST("/").WithJSON(map[string]interface{}{
+			"filter":   map[string]string{"sort": ""},
+			"settings": map[string]string{"theme": "dark"},
+		}).Expect().Status(http.StatusOK).JSON()
+
+		json.Path("$.filter").Equal(map[string]string{"sort": "", "since": "today"})
+		json.Path("$.settings.theme").Equal("dark")
+	})
+
+	t.Run("it should set the defaults of a nested struct absent from the body", func(t *testing.T) {
+		json := e.POST("/").WithQuery("since", "yesterday").WithJSON(map[string]interface{}{
+			"filter": map[string]string{"since": "tomorrow"},
+		}).Expect().Status(http.StatusOK).JSON()
+
+		json.Path("$.filter").Equal(map[string]string{"sort": "asc", "since": "yesterday"})
+		json.Path("$.settings.theme").Equal("light")
+	})
+}
+
+type requiredInput struct {
+	Page  int    `query:"page,required"`
+	Token string `header:"X-Token" required:"true"`
+	Name  string `json:"name" required:"true"`
+
+	Filter struct {
+		Sort string `json:"sort" required:"true"`
+	} `json:"filter"`
+}
+
+func requiredHandler(in *requiredInput) (*requiredInput, error) {
+	return in, nil
+}
+
+func TestRequired(t *testing.T) {
+	endpoint := kcd.New().Endpoint(requiredHandler, http.StatusOK)
+
+	r := chi.NewRouter()
+	r.Post("/", endpoint.ServeHTTP)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should report the missing fields", func(t *testing.T) {
+		json := e.POST("/").Expect().Status(http.StatusBadRequest).JSON()
+
+		json.Path("$.error_description").Equal("the request has one or multiple invalid fields")
+		json.Path("$.fields").Equal(map[string]string{
+			"page":        "is required",
+			"X-Token":     "is required",
+			"name":        "is required",
+			"filter.sort": "is required",
+		})
+	})
+
+	t.Run("it should accept the zero values", func(t *testing.T) {
+		json := e.POST("/").
+			WithQuery("page", 0).
+			WithHeader("X-Token", ValString).
+			WithJSON(map[string]interface{}{"name": "", "filter": map[string]string{"sort": ""}}).
+			Expect().
+			Status(http.StatusOK).
+			JSON()
+
+		json.Path("$.Page").Equal(0)
+		json.Path("$.Token").Equal(ValString)
+	})
+
+	t.Run("it should keep the error of a single missing field", func(t *testing.T) {
+		json := e.POST("/").
+			WithHeader("X-Token", ValString).
+			WithJSON(map[string]interface{}{"name": ValString, "filter": map[string]string{"sort": "asc"}}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON()
+
+		json.Path("$.error_description").Equal("Bad Request")
+		json.Path("$.fields").Equal(map[string]string{"page": "is required"})
+	})
+
+	t.Run("it should expose the required fields", func(t *testing.T) {
+		fields := map[string]kcd.Field{}
+		for _, field := range endpoint.Fields {
+			fields[field.Name] = field
+		}
+
+		assert.True(t, fields["Page"].Required)
+		assert.Equal(t, map[string]string{"query": "page"}, fields["Page"].Sources)
+		assert.True(t, fields["Token"].Required)
+		assert.True(t, fields["Name"].Required)
+		assert.Empty(t, fields["Name"].Sources)
+		assert.True(t, fields["Filter.Sort"].Required)
+	})
+}
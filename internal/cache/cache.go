@@ -2,7 +2,10 @@ package cache
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/alexisvisco/kcd/internal/types"
 )
@@ -38,6 +41,43 @@ func (s StructCache) String() string {
 	return string(marshal)
 }
 
+// Paths returns the paths of the tag in the struct and its children.
+func (s StructCache) Paths(tag string) map[string]bool {
+	paths := map[string]bool{}
+	s.addPaths(tag, paths)
+
+	return paths
+}
+
+// Any returns true if one of the fields of the struct or of its children satisfies f.
+func (s StructCache) Any(f func(FieldMetadata) bool) bool {
+	for _, metadata := range s.Resolvable {
+		if f(metadata) {
+			return true
+		}
+	}
+
+	for _, child := range s.Child {
+		if child.Any(f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s StructCache) addPaths(tag string, paths map[string]bool) {
+	for _, metadata := range s.Resolvable {
+		if path, ok := metadata.Paths[tag]; ok {
+			paths[path] = true
+		}
+	}
+
+	for _, child := range s.Child {
+		child.addPaths(tag, paths)
+	}
+}
+
 // FieldMetadata contains all the necessary field to decode
 type FieldMetadata struct {
 	Index                 []int
@@ -47,6 +87,16 @@ type FieldMetadata struct {
 	ArrayOrSlice          bool
 	DefaultValue          string
 	Exploder              string
+
+	// MaxSize is the maximum size in bytes of each file, from the maxsize tag.
+	MaxSize int64
+	// Accept are the accepted content types of each file, from the accept tag.
+	Accept []string
+	// From are the sources of the field by order of precedence, from the from tag.
+	From []string
+	// Required is true if the field must be present in the request, from the required option of a tag or from
+	// the required tag.
+	Required bool
 }
 
 func (f FieldMetadata) GetDefaultFieldName() string {
@@ -93,7 +143,25 @@ func (s StructAnalyzer) cache(cache *StructCache, paths TagsPath, t reflect.Type
 		fieldHasTag := false
 		currentPaths := paths.clone()
 
-		if s.lookupTags(structField, currentPaths) {
+		ownTags, required := s.lookupTags(structField, currentPaths)
+		if ownTags {
+			fieldHasTag = true
+			containTags = true
+		}
+
+		if tag, ok := structField.Tag.Lookup("required"); ok {
+			isRequired, err := strconv.ParseBool(tag)
+			if err != nil {
+				panic(fmt.Sprintf("invalid required tag for field %s: %v", structField.Name, err))
+			}
+
+			required = required || isRequired
+		}
+
+		// a required field without extractor tags is a key of the body, it is only checked by the decoder.
+		bodyRequired := required && !ownTags
+		if bodyRequired {
+			currentPaths = TagsPath{}
 			fieldHasTag = true
 			containTags = true
 		}
@@ -110,7 +178,7 @@ func (s StructAnalyzer) cache(cache *StructCache, paths TagsPath, t reflect.Type
 				containTags = true
 			}
 
-			if childStructContainTag || !metadata.ImplementUnmarshaller {
+			if !bodyRequired && (childStructContainTag || !metadata.ImplementUnmarshaller) {
 				continue
 			}
 		}
@@ -126,21 +194,77 @@ func (s StructAnalyzer) cache(cache *StructCache, paths TagsPath, t reflect.Type
 			metadata.Type = typeOfArray
 		}
 
-		if !(hasValueTag || (fieldHasTag && (metadata.ImplementUnmarshaller || types.IsUnmarshallable(metadata.Type)))) {
+		if !(hasValueTag || bodyRequired ||
+			(fieldHasTag && (metadata.ImplementUnmarshaller || types.IsUnmarshallable(metadata.Type)))) {
 			continue
 		}
 
+		metadata.Required = required
 		metadata.DefaultValue = structField.Tag.Get("default")
 		metadata.Exploder = structField.Tag.Get("exploder")
 		metadata.Paths = currentPaths
 
+		if maxSize, ok := structField.Tag.Lookup("maxsize"); ok {
+			size, err := types.ParseSize(maxSize)
+			if err != nil {
+				panic(fmt.Sprintf("invalid maxsize tag for field %s: %v", structField.Name, err))
+			}
+
+			metadata.MaxSize = size
+		}
+
+		if accept, ok := structField.Tag.Lookup("accept"); ok {
+			for _, contentType := range strings.Split(accept, ",") {
+				metadata.Accept = append(metadata.Accept, strings.TrimSpace(contentType))
+			}
+		}
+
+		if from, ok := structField.Tag.Lookup("from"); ok {
+			metadata.From = s.sources(structField.Name, from)
+		}
+
 		cache.Resolvable = append(cache.Resolvable, metadata)
 	}
 
 	return containTags
 }
 
-func (s StructAnalyzer) lookupTags(structField reflect.StructField, currentPaths TagsPath) (containTags bool) {
+// sources returns the sources of the from tag, it panics if one of them is not a tag or the body.
+func (s StructAnalyzer) sources(name, from string) []string {
+	sources := make([]string, 0)
+
+	for _, source := range strings.Split(from, ",") {
+		source = strings.TrimSpace(source)
+
+		if !s.isSource(source) {
+			panic(fmt.Sprintf("invalid from tag for field %s: unknown source %q", name, source))
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources
+}
+
+func (s StructAnalyzer) isSource(source string) bool {
+	if source == "json" || source == "body" {
+		return true
+	}
+
+	for _, tag := range s.tags {
+		if tag == source {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookupTags adds the paths of the tags of the field, without their required option.
+func (s StructAnalyzer) lookupTags(
+	structField reflect.StructField,
+	currentPaths TagsPath,
+) (containTags, required bool) {
 	var (
 		hasTags    = false
 		alreadySet = map[string]bool{}
@@ -153,12 +277,35 @@ func (s StructAnalyzer) lookupTags(structField reflect.StructField, currentPaths
 
 		lookup, ok := structField.Tag.Lookup(tag)
 		if ok {
+			var isRequired bool
+			lookup, isRequired = requiredOption(lookup)
+
 			hasTags = true
+			required = required || isRequired
 			alreadySet[tag] = true
 			currentPaths.Add(tag, lookup)
 		}
 	}
-	return hasTags
+	return hasTags, required
+}
+
+// requiredOption removes the required option from the value of a tag, the other options like the ones of the
+// cookies are kept.
+func requiredOption(value string) (string, bool) {
+	parts := strings.Split(value, ",")
+	kept := parts[:1]
+	required := false
+
+	for _, option := range parts[1:] {
+		if strings.TrimSpace(option) == "required" {
+			required = true
+			continue
+		}
+
+		kept = append(kept, option)
+	}
+
+	return strings.Join(kept, ","), required
 }
 
 func newStructCacheFromField(field reflect.StructField) StructCache {
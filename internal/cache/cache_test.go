@@ -239,6 +239,36 @@ func TestStructAnalyzer_Cache(t *testing.T) {
 				assert.Len(t, cache.Child, 0)
 			},
 		},
+		{
+			"required option removed from the path",
+			struct {
+				Page int    `query:"page,required"`
+				Name string `path:"name" required:"true"`
+				Sort string `query:"sort"`
+			}{},
+			func(cache StructCache, t *testing.T) {
+				assert.Len(t, cache.Resolvable, 3)
+				assert.Equal(t, TagsPath{"query": "page"}, cache.Resolvable[0].Paths)
+				assert.True(t, cache.Resolvable[0].Required)
+				assert.True(t, cache.Resolvable[1].Required)
+				assert.False(t, cache.Resolvable[2].Required)
+			},
+		},
+		{
+			"required body key without extractor tag",
+			struct {
+				Name     string               `json:"name" required:"true"`
+				Settings structNotUnmarshable `json:"settings" required:"true"`
+				Optional string               `json:"optional"`
+			}{},
+			func(cache StructCache, t *testing.T) {
+				assert.Len(t, cache.Resolvable, 2)
+				assert.Empty(t, cache.Resolvable[0].Paths)
+				assert.True(t, cache.Resolvable[0].Required)
+				assert.Equal(t, []int{1}, cache.Resolvable[1].Index)
+				assert.Len(t, cache.Child, 0)
+			},
+		},
 	}
 
 	for _, assertion := range tableTesting {
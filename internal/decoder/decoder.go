@@ -1,11 +1,13 @@
 package decoder
 
 import (
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"reflect"
-	"strings"
 
 	"github.com/alexisvisco/kcd/internal/cache"
+	"github.com/alexisvisco/kcd/internal/types"
 	"github.com/alexisvisco/kcd/pkg/extractor"
 )
 
@@ -16,6 +18,24 @@ type Decoder struct {
 
 	stringsExtractors []extractor.Strings
 	valueExtractors   []extractor.Value
+	options           Options
+
+	bindings *[]Binding
+}
+
+// Binding is the provenance of the value of a field, see Decoder.Bindings.
+type Binding struct {
+	// Field is the path of the field in the input struct, embedded structs are not part of it.
+	Field string
+
+	// Source is the tag of the extractor which supplied the value, or "default", empty if there is no value.
+	Source string
+
+	// Path is the path used by the extractor, for instance the name of the query parameter.
+	Path string
+
+	// Values are the raw values supplied by the extractor.
+	Values []string
 }
 
 // NewDecoder create a new Decoder.
@@ -24,15 +44,23 @@ func NewDecoder(
 	res http.ResponseWriter,
 	stringsExtractors []extractor.Strings,
 	valueExtractors []extractor.Value,
+	options Options,
 ) *Decoder {
 	return &Decoder{
 		req:               req,
 		res:               res,
 		stringsExtractors: stringsExtractors,
 		valueExtractors:   valueExtractors,
+		options:           options,
+		bindings:          &[]Binding{},
 	}
 }
 
+// Bindings returns the provenance of the fields decoded by Decode, in the order of the decoding.
+func (d Decoder) Bindings() []Binding {
+	return *d.bindings
+}
+
 type previousFields struct {
 	root          reflect.Value
 	uninitialized [][]int
@@ -59,18 +87,79 @@ func (d previousFields) getCurrentReflectValue() reflect.Value {
 	return field
 }
 
+// lookup returns the field at the index of the current struct, it is invalid if a pointer to the current struct
+// is nil. Unlike getCurrentReflectValue, it does not initialize the pointers.
+func (d previousFields) lookup(index []int) reflect.Value {
+	var field = d.root
+
+	indexes := make([][]int, 0, len(d.uninitialized)+1)
+	indexes = append(indexes, d.uninitialized...)
+
+	for _, fieldIndex := range append(indexes, index) {
+		for field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return reflect.Value{}
+			}
+
+			field = field.Elem()
+		}
+
+		field = field.FieldByIndex(fieldIndex)
+	}
+
+	return field
+}
+
 // Decode will from cache struct and a root value decode the http request/response
 // and set all extracted values inside the root parameter.
+//
+// The fields which fail to decode do not stop the decoding, their errors are aggregated in a single input error
+// with the messages by field in its "fields" field, see FieldErrors. The other errors are returned right away.
 func (d Decoder) Decode(c cache.StructCache, root reflect.Value) error {
-	return d.decode(c, root.Type(), previousFields{root: root})
+	var errs []error
+
+	if err := d.decode(c, root.Type(), previousFields{root: root}, &errs, location{}); err != nil {
+		return err
+	}
+
+	return aggregate(errs)
 }
 
-func (d Decoder) decode(c cache.StructCache, root reflect.Type, prev previousFields) error {
+func (d Decoder) decode(
+	c cache.StructCache,
+	root reflect.Type,
+	prev previousFields,
+	errs *[]error,
+	parent location,
+) error {
 	fieldsToSet := make([]setterContext, 0, len(c.Resolvable))
 
+	structType := root
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
 	for _, metadata := range c.Resolvable {
-		decodingStrategy, path, v, err := d.getValueFromHTTP(metadata)
+		structField := structType.FieldByIndex(metadata.Index)
+		jsonPath := parent.jsonPath(structField)
+		decodingStrategy, path, v, err := d.getValueFromHTTP(metadata, structField, jsonPath, prev.lookup(metadata.Index))
+		if err == nil && decodingStrategy == "" && metadata.Required {
+			err = d.missing(metadata, structField, jsonPath)
+		}
+
+		*d.bindings = append(*d.bindings, Binding{
+			Field:  fieldPath(parent.field, structField.Name),
+			Source: decodingStrategy,
+			Path:   path,
+			Values: bindingValues(v),
+		})
+
 		if err != nil {
+			if _, ok := FieldErrors(err); ok {
+				*errs = append(*errs, err)
+				continue
+			}
+
 			return err
 		}
 
@@ -102,6 +191,11 @@ func (d Decoder) decode(c cache.StructCache, root reflect.Type, prev previousFie
 			}
 
 			if err := newFieldSetter(field, setterCtx).set(); err != nil {
+				if _, ok := FieldErrors(err); ok {
+					*errs = append(*errs, err)
+					continue
+				}
+
 				return err
 			}
 		}
@@ -123,7 +217,13 @@ func (d Decoder) decode(c cache.StructCache, root reflect.Type, prev previousFie
 			newPreviousFields.uninitialized = append(newPreviousFields.uninitialized, structCache.Index)
 		}
 
-		if err := d.decode(structCache, newRoot, newPreviousFields); err != nil {
+		if err := d.decode(
+			structCache,
+			newRoot,
+			newPreviousFields,
+			errs,
+			parent.child(structType.FieldByIndex(structCache.Index)),
+		); err != nil {
 			return err
 		}
 	}
@@ -131,62 +231,68 @@ func (d Decoder) decode(c cache.StructCache, root reflect.Type, prev previousFie
 	return nil
 }
 
-func (d Decoder) getValueFromHTTP(r cache.FieldMetadata) (decodingStrategy, key string, val interface{}, err error) {
-	for _, e := range d.stringsExtractors {
-		path, ok := r.Paths[e.Tag()]
-		if ok {
-			list, err := e.Extract(d.req, d.res, path)
-			if err != nil {
-				return "", "", nil, err
-			}
+// bindingValues returns the raw values of a value supplied by an extractor.
+func bindingValues(v interface{}) []string {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case []string:
+		return t
+	case string:
+		return []string{t}
+	case []*multipart.FileHeader:
+		names := make([]string, 0, len(t))
+		for _, file := range t {
+			names = append(names, file.Filename)
+		}
 
-			if len(list) == 0 {
-				continue
-			}
+		return names
+	}
 
-			if len(r.Exploder) > 0 && len(list) == 1 && r.ArrayOrSlice {
-				list = strings.Split(list[0], r.Exploder)
-			}
+	return []string{fmt.Sprint(v)}
+}
 
-			return e.Tag(), path, list, nil
-		}
+func fieldPath(parent, name string) string {
+	if parent == "" {
+		return name
 	}
 
-	for _, e := range d.valueExtractors {
-		path, ok := r.Paths[e.Tag()]
-		if ok {
-			v, err := e.Extract(d.req, d.res, path)
-			if err != nil {
-				return "", "", nil, err
-			}
+	return parent + "." + name
+}
 
-			if len(r.Exploder) > 0 && r.ArrayOrSlice {
-				if t, ok := v.(string); ok {
-					list := strings.Split(t, r.Exploder)
-					if len(list) > 1 {
-						return "", "", list, nil
-					}
-				}
-			}
+// location is the path of a struct in the input, by go names and by json names.
+type location struct {
+	field, json string
 
-			if v != nil {
-				return e.Tag(), path, v, nil
-			}
-		}
+	// noJSON is true if the struct is not part of a json body.
+	noJSON bool
+}
+
+// child returns the location of the struct of the field, the fields of an embedded struct are promoted.
+func (l location) child(f reflect.StructField) location {
+	name, ok := types.JSONName(f)
+
+	child := l
+	if !f.Anonymous {
+		child.field = fieldPath(l.field, f.Name)
 	}
 
-	if len(r.DefaultValue) > 0 {
-		def := r.DefaultValue
+	switch {
+	case !ok:
+		child.noJSON = true
+	case !f.Anonymous || f.Tag.Get("json") != "":
+		child.json = fieldPath(l.json, name)
+	}
 
-		if len(r.Exploder) > 0 && r.ArrayOrSlice {
-			list := strings.Split(def, r.Exploder)
-			if len(list) > 1 {
-				return "default", r.GetDefaultFieldName(), list, nil
-			}
-		}
+	return child
+}
 
-		return "default", r.GetDefaultFieldName(), def, nil
+// jsonPath returns the path of json names of the field, empty if the field is not part of a json body.
+func (l location) jsonPath(f reflect.StructField) string {
+	name, ok := types.JSONName(f)
+	if !ok || l.noJSON {
+		return ""
 	}
 
-	return "", "", nil, nil
+	return fieldPath(l.json, name)
 }
@@ -0,0 +1,49 @@
+package decoder
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alexisvisco/kcd/internal/cache"
+	"github.com/alexisvisco/kcd/internal/kcderr"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// DefaultProvider returns the value of a default tag computed by a provider, see ParseDefaultProvider.
+type DefaultProvider func(ctx context.Context, name, arg string) (string, error)
+
+// ParseDefaultProvider returns the name and the argument of the provider of a default tag like "@env:REGION".
+// It returns false for a literal default, "@@" escapes a literal default starting with "@".
+func ParseDefaultProvider(def string) (name, arg string, ok bool) {
+	if !strings.HasPrefix(def, "@") || strings.HasPrefix(def, "@@") {
+		return "", "", false
+	}
+
+	name, arg, _ = strings.Cut(def[1:], ":")
+
+	return name, arg, true
+}
+
+// defaultValue returns the default value of the field, computed by its provider if any.
+func (d Decoder) defaultValue(r cache.FieldMetadata) (string, error) {
+	name, arg, ok := ParseDefaultProvider(r.DefaultValue)
+	if !ok {
+		return strings.TrimPrefix(r.DefaultValue, "@"), nil
+	}
+
+	if d.options.DefaultProvider == nil {
+		return "", errors.NewWithKind(kcderr.InputCritical, "no provider for the default value %q", r.DefaultValue).
+			WithField("decoding-strategy", "default").
+			WithField("path", r.GetDefaultFieldName())
+	}
+
+	value, err := d.options.DefaultProvider(d.req.Context(), name, arg)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to compute the default value %q", r.DefaultValue).
+			WithKind(kcderr.InputCritical).
+			WithField("decoding-strategy", "default").
+			WithField("path", r.GetDefaultFieldName())
+	}
+
+	return value, nil
+}
@@ -0,0 +1,97 @@
+package decoder
+
+import (
+	"github.com/alexisvisco/kcd/internal/kcderr"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// FieldErrors returns the messages by field of an input error, read from its path and its "fields" field.
+// It returns false if the error is not about fields of the input.
+func FieldErrors(err error) (map[string]string, bool) {
+	e, ok := err.(*errors.Error)
+	if !ok || e.Kind != kcderr.Input {
+		return nil, false
+	}
+
+	fields := map[string]string{}
+
+	if path, _ := e.GetField("path"); path != nil {
+		if path, ok := path.(string); ok && path != "" {
+			fields[path] = e.Message
+		}
+	}
+
+	if messages, ok := e.GetField("fields"); ok {
+		if messages, ok := messages.(map[string]string); ok {
+			for key, message := range messages {
+				fields[key] = message
+			}
+		}
+	}
+
+	return fields, len(fields) > 0
+}
+
+// MergeFieldErrors returns an input error with the messages by field of err and the ones of fields for the fields
+// err is not about. It returns err itself when there is nothing to add.
+func MergeFieldErrors(err error, fields map[string]string) error {
+	merged, ok := FieldErrors(err)
+	if !ok {
+		return err
+	}
+
+	added := false
+
+	for key, message := range fields {
+		if _, exist := merged[key]; !exist {
+			merged[key] = message
+			added = true
+		}
+	}
+
+	if !added {
+		return err
+	}
+
+	return fieldsError(merged, []error{err})
+}
+
+// Join returns the errors of the fields of errs in a single error, like Decode does. The nil errors are ignored.
+func Join(errs ...error) error {
+	list := make([]error, 0, len(errs))
+
+	for _, err := range errs {
+		if err != nil {
+			list = append(list, err)
+		}
+	}
+
+	return aggregate(list)
+}
+
+// aggregate returns the error of the fields which failed to decode, the error itself if there is only one.
+func aggregate(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	fields := map[string]string{}
+
+	for _, err := range errs {
+		messages, _ := FieldErrors(err)
+		for key, message := range messages {
+			fields[key] = message
+		}
+	}
+
+	return fieldsError(fields, errs)
+}
+
+func fieldsError(fields map[string]string, errs []error) error {
+	return errors.NewWithKind(kcderr.Input, "the request has one or multiple invalid fields").
+		WithField("fields", fields).
+		WithField("errors", errs)
+}
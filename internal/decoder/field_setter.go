@@ -4,8 +4,11 @@ import (
 	"encoding"
 	"encoding/json"
 	"fmt"
+	"mime"
+	"mime/multipart"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alexisvisco/kcd/internal/cache"
@@ -41,6 +44,10 @@ func newFieldSetter(field reflect.Value, setterCtx setterContext) *fieldSetter {
 	return fs
 }
 func (f fieldSetter) set() error {
+	if files, ok := f.value.([]*multipart.FileHeader); ok {
+		return f.setFiles(files)
+	}
+
 	if f.field.Type().AssignableTo(reflect.TypeOf(f.value)) {
 		f.field.Set(reflect.ValueOf(f.value))
 		return nil
@@ -71,6 +78,80 @@ func (f fieldSetter) set() error {
 	return f.setForNormalType(list[0], isPtr)
 }
 
+var (
+	fileHeaderType      = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader{})
+	fileType            = reflect.TypeOf((*multipart.File)(nil)).Elem()
+)
+
+// setFiles checks the limits of the uploaded files and sets them as a []*multipart.FileHeader,
+// a *multipart.FileHeader, a multipart.FileHeader or as the opened file for an interface like io.ReadCloser.
+func (f fieldSetter) setFiles(files []*multipart.FileHeader) error {
+	for _, file := range files {
+		if err := f.checkFile(file); err != nil {
+			return err
+		}
+	}
+
+	fieldType := f.field.Type()
+
+	switch {
+	case fileHeaderSliceType.AssignableTo(fieldType):
+		f.field.Set(reflect.ValueOf(files))
+	case fileHeaderType.AssignableTo(fieldType):
+		f.field.Set(reflect.ValueOf(files[0]))
+	case fileHeaderType.Elem().AssignableTo(fieldType):
+		f.field.Set(reflect.ValueOf(files[0]).Elem())
+	case fieldType.Kind() == reflect.Interface && fileType.Implements(fieldType):
+		file, err := files[0].Open()
+		if err != nil {
+			return errors.Wrap(err, "unable to open file").WithKind(kcderr.InputCritical).WithFields(f.errFields)
+		}
+
+		f.field.Set(reflect.ValueOf(file))
+	default:
+		return errors.NewWithKind(kcderr.InputCritical, "incompatible type for a file").WithFields(f.errFields)
+	}
+
+	return nil
+}
+
+// checkFile checks the size and the declared content type of a file against the maxsize and accept tags.
+func (f fieldSetter) checkFile(file *multipart.FileHeader) error {
+	if f.metadata.MaxSize > 0 && file.Size > f.metadata.MaxSize {
+		return errors.NewWithKind(kcderr.Input, "file is too large (max %s)", types.FormatSize(f.metadata.MaxSize)).
+			WithFields(f.errFields)
+	}
+
+	if len(f.metadata.Accept) == 0 {
+		return nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(file.Header.Get("Content-Type"))
+
+	for _, accepted := range f.metadata.Accept {
+		if matchMediaType(accepted, contentType) {
+			return nil
+		}
+	}
+
+	return errors.NewWithKind(kcderr.Input, "unsupported content type %q", contentType).
+		WithFields(f.errFields)
+}
+
+// matchMediaType returns true if the media type matches the pattern, the pattern may be "*/*" or "type/*".
+func matchMediaType(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	}
+
+	return false
+}
+
 func (f fieldSetter) setForArrayOrSlice(ptr bool, list []string) error {
 	var (
 		element reflect.Value
@@ -167,6 +248,11 @@ func (f fieldSetter) setForNormalType(str string, ptr bool) error {
 			return err
 		}
 
+		// the unmarshaller is a pointer, a field like time.Time holds its value.
+		if f.field.Kind() != reflect.Ptr {
+			withUnmarshaller = withUnmarshaller.Elem()
+		}
+
 		f.field.Set(withUnmarshaller)
 	case types.IsNative(f.metadata.Type):
 		native, err := f.makeNative(str, ptr)
@@ -0,0 +1,264 @@
+package decoder
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/alexisvisco/kcd/internal/cache"
+	"github.com/alexisvisco/kcd/internal/kcderr"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// bodySources are the names of the source of the fields set by the bind hook.
+var bodySources = map[string]bool{"json": true, "body": true}
+
+// Options are the options of a Decoder.
+type Options struct {
+	// SourceOrder is the order of the sources of the fields, before the order of the extractors.
+	// The "from" tag of a field has the priority over it.
+	SourceOrder []string
+
+	// RejectAmbiguous rejects the fields with a value from two sources, the default tag excepted.
+	RejectAmbiguous bool
+
+	// DefaultProvider computes the default tags starting with "@", see ParseDefaultProvider.
+	DefaultProvider DefaultProvider
+
+	// Body tells which fields are present in the body, a field with a non zero value is considered present
+	// when it is nil or when it does not track the body.
+	Body Body
+}
+
+// Body tells which fields are present in the body, see hook.BodyFields.
+type Body interface {
+	// Tracked returns true if the fields of the body were recorded.
+	Tracked() bool
+
+	// Has returns true if the field, a path of json names, is present in the body.
+	Has(path string) bool
+
+	// Paths returns the paths of the fields present in the body.
+	Paths() []string
+}
+
+// source is a value found for a field.
+type source struct {
+	strategy, path string
+	value          interface{}
+}
+
+// sources returns the sources of the field by order of precedence: the sources of the from tag, the ones of the
+// source order of the options, then the extractors and the default tag.
+// The body is a source only if it is part of one of the orders or if ambiguous fields are rejected.
+func (d Decoder) sources(r cache.FieldMetadata) []string {
+	order := make([]string, 0, len(r.From)+len(d.options.SourceOrder)+len(d.stringsExtractors)+len(d.valueExtractors)+2)
+	seen := map[string]bool{}
+
+	add := func(tags ...string) {
+		for _, tag := range tags {
+			if bodySources[tag] {
+				tag = "json"
+			}
+
+			if !seen[tag] {
+				seen[tag] = true
+				order = append(order, tag)
+			}
+		}
+	}
+
+	add(r.From...)
+	add(d.options.SourceOrder...)
+
+	for _, e := range d.stringsExtractors {
+		add(e.Tag())
+	}
+
+	for _, e := range d.valueExtractors {
+		add(e.Tag())
+	}
+
+	if d.options.RejectAmbiguous {
+		add("json")
+	}
+
+	add("default")
+
+	return order
+}
+
+// getValueFromHTTP returns the value of the field from its first source. A value set by the bind hook is returned
+// as a nil value from the "json" source, the field must not be set.
+func (d Decoder) getValueFromHTTP(
+	r cache.FieldMetadata,
+	field reflect.StructField,
+	jsonPath string,
+	current reflect.Value,
+) (decodingStrategy, key string, val interface{}, err error) {
+	var first *source
+
+	for _, tag := range d.sources(r) {
+		// the default is not a source of an ambiguity, it is not evaluated once a source has a value.
+		if tag == "default" && first != nil {
+			break
+		}
+
+		found, err := d.extract(tag, r, field, jsonPath, current)
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		if found == nil {
+			continue
+		}
+
+		if first == nil {
+			first = found
+
+			if !d.options.RejectAmbiguous {
+				break
+			}
+
+			continue
+		}
+
+		return "", "", nil, errors.
+			NewWithKind(kcderr.Input, "ambiguous value from %s and %s", first.strategy, found.strategy).
+			WithField("decoding-strategy", first.strategy).
+			WithField("path", first.path).
+			WithField("sources", []string{first.strategy, found.strategy})
+	}
+
+	if first == nil {
+		return "", "", nil, nil
+	}
+
+	return first.strategy, first.path, first.value, nil
+}
+
+// missing returns the error of a required field without a value, it is about the path of its first source or
+// about its json path for a key of the body.
+func (d Decoder) missing(r cache.FieldMetadata, field reflect.StructField, jsonPath string) error {
+	strategy, path := "json", jsonPath
+	if path == "" {
+		path = field.Name
+	}
+
+	for _, tag := range d.sources(r) {
+		if p, ok := r.Paths[tag]; ok && tag != "default" {
+			// options of the tag, like a signed cookie, are not part of the name.
+			strategy, path = tag, strings.Split(p, ",")[0]
+			break
+		}
+	}
+
+	return errors.NewWithKind(kcderr.Input, "is required").
+		WithField("decoding-strategy", strategy).
+		WithField("path", path)
+}
+
+// extract returns the value of the field from the source tag, nil if there is none.
+// The default tag only applies to a field absent from every source, the body included.
+func (d Decoder) extract(
+	tag string,
+	r cache.FieldMetadata,
+	field reflect.StructField,
+	jsonPath string,
+	current reflect.Value,
+) (*source, error) {
+	if tag == "json" || (tag == "default" && d.inBody(jsonPath, current)) {
+		if !d.inBody(jsonPath, current) {
+			return nil, nil
+		}
+
+		path := jsonPath
+		if path == "" {
+			path = field.Name
+		}
+
+		return &source{strategy: "json", path: path}, nil
+	}
+
+	if tag == "default" {
+		if len(r.DefaultValue) == 0 {
+			return nil, nil
+		}
+
+		def, err := d.defaultValue(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(r.Exploder) > 0 && r.ArrayOrSlice {
+			list := strings.Split(def, r.Exploder)
+			if len(list) > 1 {
+				return &source{strategy: "default", path: r.GetDefaultFieldName(), value: list}, nil
+			}
+		}
+
+		return &source{strategy: "default", path: r.GetDefaultFieldName(), value: def}, nil
+	}
+
+	path, ok := r.Paths[tag]
+	if !ok {
+		return nil, nil
+	}
+
+	for _, e := range d.stringsExtractors {
+		if e.Tag() != tag {
+			continue
+		}
+
+		list, err := e.Extract(d.req, d.res, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(list) == 0 {
+			return nil, nil
+		}
+
+		if len(r.Exploder) > 0 && len(list) == 1 && r.ArrayOrSlice {
+			list = strings.Split(list[0], r.Exploder)
+		}
+
+		return &source{strategy: tag, path: path, value: list}, nil
+	}
+
+	for _, e := range d.valueExtractors {
+		if e.Tag() != tag {
+			continue
+		}
+
+		v, err := e.Extract(d.req, d.res, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(r.Exploder) > 0 && r.ArrayOrSlice {
+			if t, ok := v.(string); ok {
+				list := strings.Split(t, r.Exploder)
+				if len(list) > 1 {
+					return &source{strategy: tag, path: path, value: list}, nil
+				}
+			}
+		}
+
+		if v == nil {
+			return nil, nil
+		}
+
+		return &source{strategy: tag, path: path, value: v}, nil
+	}
+
+	return nil, nil
+}
+
+// inBody returns true if the field at the path of json names was set from the body.
+func (d Decoder) inBody(jsonPath string, current reflect.Value) bool {
+	if d.options.Body != nil && d.options.Body.Tracked() {
+		return jsonPath != "" && d.options.Body.Has(jsonPath)
+	}
+
+	return current.IsValid() && !current.IsZero()
+}
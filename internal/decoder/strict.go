@@ -0,0 +1,108 @@
+package decoder
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/alexisvisco/kcd/internal/cache"
+	"github.com/alexisvisco/kcd/internal/kcderr"
+	"github.com/alexisvisco/kcd/internal/types"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// CheckUnknownParameters returns an error if the request has query parameters which are not bound to a field of
+// the struct cache, or fields in the body which are not part of the input type t. The fields of the body are
+// the ones of the Body of the options, by their full path like "filter.name".
+// The unknown parameters are in the "fields" field of the error.
+func (d Decoder) CheckUnknownParameters(c cache.StructCache, t reflect.Type) error {
+	known := c.Paths("query")
+	unknown := map[string]string{}
+
+	for key := range d.req.URL.Query() {
+		if !known[key] {
+			unknown[key] = "unknown parameter"
+		}
+	}
+
+	if d.options.Body != nil {
+		for _, path := range d.options.Body.Paths() {
+			// only the first unknown field of a path is reported.
+			parent := ""
+			if i := strings.LastIndex(path, "."); i >= 0 {
+				parent = path[:i]
+			}
+
+			if !knownJSONPath(t, path) && (parent == "" || knownJSONPath(t, parent)) {
+				unknown[path] = "unknown parameter"
+			}
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(unknown))
+	for key := range unknown {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return errors.NewWithKind(kcderr.Input, "the request has unknown parameters").
+		WithField("unknown-parameters", keys).
+		WithField("fields", unknown)
+}
+
+// knownJSONPath returns true if the path of json names is part of the type t. The keys of maps and of the types
+// implementing json.Unmarshaler are all known.
+func knownJSONPath(t reflect.Type, path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+			return true
+		}
+
+		field, ok := types.JSONField(t, key)
+		if !ok {
+			return false
+		}
+
+		t = field.Type
+	}
+
+	return true
+}
+
+// FieldPath returns the path of go names of the field of the type t at the path of json names, like "Filter.Sort"
+// for "filter.sort". It returns false if the path is not a field of the structs of t.
+func FieldPath(t reflect.Type, jsonPath string) (string, bool) {
+	names := make([]string, 0, strings.Count(jsonPath, ".")+1)
+
+	for _, key := range strings.Split(jsonPath, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+			return "", false
+		}
+
+		field, ok := types.JSONField(t, key)
+		if !ok {
+			return "", false
+		}
+
+		names = append(names, field.Name)
+		t = field.Type
+	}
+
+	return strings.Join(names, "."), true
+}
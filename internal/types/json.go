@@ -0,0 +1,52 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONName returns the name of the field in a json body, false if the field is ignored.
+func JSONName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+
+	return f.Name, true
+}
+
+// JSONField returns the field of the struct t with the json name key, the names are compared without case like
+// encoding/json. The fields of the embedded structs without json name are promoted.
+func JSONField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, ok := JSONName(field)
+		if !ok || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		embedded := field.Type
+		for embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" && embedded.Kind() == reflect.Struct {
+			if promoted, ok := JSONField(embedded, key); ok {
+				return promoted, true
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
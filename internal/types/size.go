@@ -0,0 +1,50 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size in bytes with an optional unit: B, KB, MB or GB (powers of 1024), for instance "5MB".
+func ParseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(str, unit.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, unit.suffix))
+			multiplier = unit.multiplier
+
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return size * multiplier, nil
+}
+
+// FormatSize formats a size in bytes with the largest unit that represents it exactly.
+func FormatSize(size int64) string {
+	for _, unit := range sizeUnits {
+		if size >= unit.multiplier && size%unit.multiplier == 0 {
+			return strconv.FormatInt(size/unit.multiplier, 10) + unit.suffix
+		}
+	}
+
+	return strconv.FormatInt(size, 10) + "B"
+}
@@ -1,6 +1,9 @@
 package kcd
 
 import (
+	"time"
+
+	"github.com/alexisvisco/kcd/pkg/errors"
 	"github.com/alexisvisco/kcd/pkg/extractor"
 	"github.com/alexisvisco/kcd/pkg/hook"
 )
@@ -15,22 +18,342 @@ type Configuration struct {
 	ValidateHook hook.ValidateHook
 	RenderHook   hook.RenderHook
 	LogHook      hook.LogHook
+	TraceHook    hook.TraceHook
+
+	// Codecs are the codecs the default hooks negotiate with the client, hook.DefaultCodecs if empty.
+	Codecs hook.Codecs
+
+	// Strict rejects the requests with unknown query parameters or unknown fields in the body.
+	Strict bool
+
+	// NoBody tells the bind hook to ignore the body, for routes like GET or DELETE which expect none.
+	NoBody bool
+
+	// SourceOrder is the order of precedence of the sources of the fields, like "json" for the body, "header" or
+	// "default". The sources not listed come after, in the order of the extractors.
+	// The from tag of a field, like `from:"header,query,default"`, has the priority over it.
+	SourceOrder []string
+
+	// DefaultProviders compute the default tags starting with "@" by name, like `default:"@uuid"`.
+	// A default tag starting with "@@" is the literal value with a single "@".
+	DefaultProviders map[string]DefaultProvider
+
+	// RejectAmbiguous rejects the requests with a field supplied by two sources, the body included.
+	RejectAmbiguous bool
+
+	// Compression enables the compression of the responses negotiated from the Accept-Encoding header when not nil.
+	Compression *hook.Compression
 
+	// ETag sends an ETag computed from the rendered body and answers 304 Not Modified to matching requests.
+	ETag bool
+
+	// MaxBodyBytes overrides the body limit of the bind hook when greater than zero.
+	MaxBodyBytes int64
+
+	// KindStatus overrides the http status code of error kinds.
+	KindStatus map[errors.Kind]int
+
+	// Timeout is the maximum duration of a handler, the context of the request is canceled after it.
+	// There is no timeout when zero.
+	Timeout time.Duration
+
+	// Verbose sends a trace of the binding of each request to the trace hook, see BindingInfo.
 	Verbose bool
 }
 
-// Config is the instance of Configuration type.
+// Config is the instance of Configuration type used by the package level Handler and Handle functions.
 // You can add as many extractor you want, modify them ...
 // You can set your custom hook too.
-var Config = Configuration{
-	StringsExtractors: []extractor.Strings{extractor.Path{}, extractor.Header{}, extractor.Query{}},
-	ValueExtractors:   []extractor.Value{extractor.Context{}},
+//
+// Config is read at each request by the package level handlers, the tags of the inputs are read when a handler
+// is created with the extractors of Config. Use New to get an Engine with its own configuration.
+var Config = DefaultConfiguration()
+
+// DefaultConfiguration returns a new instance of the default configuration.
+func DefaultConfiguration() Configuration {
+	return Configuration{
+		StringsExtractors: []extractor.Strings{
+			extractor.Path{}, extractor.Header{}, extractor.Query{}, extractor.Form{}, extractor.Cookie{},
+		},
+		ValueExtractors: []extractor.Value{extractor.Context{}, extractor.File{}},
+
+		Codecs:           hook.DefaultCodecs,
+		DefaultProviders: defaultProviders(),
+
+		ErrorHook:    hook.Error,
+		RenderHook:   hook.Render,
+		BindHook:     hook.Bind(256 * 1024),
+		ValidateHook: hook.Validate,
+		LogHook:      hook.Log,
+		TraceHook:    hook.Trace,
+	}
+}
+
+// Engine wraps kcd handlers with its own configuration.
+// Multiple engines can live side by side, for instance a public and an internal API with different hooks.
+type Engine struct {
+	config Configuration
+
+	// global is true for the engines of the package level Config, they read it at each request with their
+	// options, see Default.
+	global  bool
+	options []Option
+}
+
+// Option modifies the configuration of an Engine.
+type Option func(c *Configuration)
 
-	ErrorHook:    hook.Error,
-	RenderHook:   hook.Render,
-	BindHook:     hook.Bind(256 * 1024),
-	ValidateHook: hook.Validate,
-	LogHook:      hook.Log,
+// New returns an Engine using the default configuration modified by the options.
+func New(options ...Option) *Engine {
+	return (&Engine{config: DefaultConfiguration()}).with(options...)
+}
+
+// with returns a copy of the engine modified by the options, the engine itself is left untouched.
+func (e *Engine) with(options ...Option) *Engine {
+	if len(options) == 0 {
+		return e
+	}
+
+	config := e.config
+	config.KindStatus = make(map[errors.Kind]int, len(e.config.KindStatus))
+
+	for kind, status := range e.config.KindStatus {
+		config.KindStatus[kind] = status
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	engine := &Engine{config: config}
+	if e.global {
+		engine.global = true
+		engine.options = append(append([]Option(nil), e.options...), options...)
+	}
+
+	return engine
+}
+
+// Default returns an Engine using the package level Config.
+// Like the package level handlers, its handlers read the hooks, the extractors and the options of Config at each
+// request, the tags of the inputs are read when a handler is created.
+func Default() *Engine {
+	return &Engine{config: Config, global: true}
+}
+
+// WithStringsExtractors replaces the strings extractors.
+func WithStringsExtractors(extractors ...extractor.Strings) Option {
+	return func(c *Configuration) {
+		c.StringsExtractors = extractors
+	}
+}
+
+// WithValueExtractors replaces the value extractors.
+func WithValueExtractors(extractors ...extractor.Value) Option {
+	return func(c *Configuration) {
+		c.ValueExtractors = extractors
+	}
+}
+
+// WithPathExtractor replaces the extractor of the path tag, for instance extractor.MuxPath to use the
+// gorilla/mux router instead of chi.
+func WithPathExtractor(e extractor.Strings) Option {
+	return func(c *Configuration) {
+		c.StringsExtractors = replaceStringsExtractor(c.StringsExtractors, e)
+	}
+}
+
+// WithCookieKeys sets the keys of the cookie extractor, used to verify signed cookies and decrypt encrypted cookies.
+func WithCookieKeys(hashKey, blockKey []byte) Option {
+	return func(c *Configuration) {
+		c.StringsExtractors = replaceStringsExtractor(c.StringsExtractors, extractor.Cookie{
+			HashKey:  hashKey,
+			BlockKey: blockKey,
+		})
+	}
+}
+
+// replaceStringsExtractor returns a copy of extractors where the extractor with the same tag as e is replaced
+// by e, e is added if there is no such extractor.
+func replaceStringsExtractor(extractors []extractor.Strings, e extractor.Strings) []extractor.Strings {
+	replaced := make([]extractor.Strings, 0, len(extractors)+1)
+	found := false
+
+	for _, current := range extractors {
+		if current.Tag() == e.Tag() {
+			current = e
+			found = true
+		}
+
+		replaced = append(replaced, current)
+	}
+
+	if !found {
+		replaced = append(replaced, e)
+	}
+
+	return replaced
+}
+
+// WithErrorHook replaces the error hook.
+func WithErrorHook(h hook.ErrorHook) Option {
+	return func(c *Configuration) {
+		c.ErrorHook = h
+	}
+}
+
+// WithBindHook replaces the bind hook.
+func WithBindHook(h hook.BindHook) Option {
+	return func(c *Configuration) {
+		c.BindHook = h
+	}
+}
+
+// WithValidateHook replaces the validate hook.
+func WithValidateHook(h hook.ValidateHook) Option {
+	return func(c *Configuration) {
+		c.ValidateHook = h
+	}
+}
+
+// WithRenderHook replaces the render hook.
+func WithRenderHook(h hook.RenderHook) Option {
+	return func(c *Configuration) {
+		c.RenderHook = h
+	}
+}
+
+// WithLogHook replaces the log hook.
+func WithLogHook(h hook.LogHook) Option {
+	return func(c *Configuration) {
+		c.LogHook = h
+	}
+}
+
+// WithTraceHook replaces the trace hook.
+func WithTraceHook(h hook.TraceHook) Option {
+	return func(c *Configuration) {
+		c.TraceHook = h
+	}
+}
+
+// WithMaxBodyBytes overrides the maximum number of bytes the bind hook reads from the body.
+func WithMaxBodyBytes(n int64) Option {
+	return func(c *Configuration) {
+		c.MaxBodyBytes = n
+	}
+}
+
+// WithCodecs registers codecs, they replace the codecs of the same media type.
+// The default codecs only have json, formats like xml are opt-in: WithCodecs(hook.XMLCodec{}).
+func WithCodecs(codecs ...hook.Codec) Option {
+	return func(c *Configuration) {
+		if len(c.Codecs) == 0 {
+			c.Codecs = hook.DefaultCodecs
+		}
+
+		c.Codecs = c.Codecs.With(codecs...)
+	}
+}
+
+// WithStrict sets the strict mode, it rejects the requests with unknown query parameters or unknown fields in
+// the body. The unknown fields of a json body are reported by their path, like "filter.name", and the unknown
+// parameters are reported with the fields which failed to decode.
+func WithStrict(strict bool) Option {
+	return func(c *Configuration) {
+		c.Strict = strict
+	}
+}
+
+// WithSourceOrder sets the order of precedence of the sources of the fields, see Configuration.SourceOrder.
+// For instance kcd.WithSourceOrder("json") prevents the extractors from overriding the fields of the body.
+func WithSourceOrder(sources ...string) Option {
+	return func(c *Configuration) {
+		c.SourceOrder = sources
+	}
+}
+
+// WithDefaultProvider registers the provider of the default tags `default:"@name"`, it replaces the provider
+// with the same name, for instance:
+//
+//	kcd.WithDefaultProvider("tenantDefaultLimit", func(ctx context.Context, _ string) (string, error) {
+//		return strconv.Itoa(tenantFromContext(ctx).DefaultLimit), nil
+//	})
+func WithDefaultProvider(name string, provider DefaultProvider) Option {
+	return func(c *Configuration) {
+		providers := make(map[string]DefaultProvider, len(c.DefaultProviders)+1)
+		for key, value := range c.DefaultProviders {
+			providers[key] = value
+		}
+
+		providers[name] = provider
+		c.DefaultProviders = providers
+	}
+}
+
+// WithRejectAmbiguous rejects the requests with a field supplied by two sources, like a query parameter and a
+// field of the body, the default tag excepted.
+func WithRejectAmbiguous(reject bool) Option {
+	return func(c *Configuration) {
+		c.RejectAmbiguous = reject
+	}
+}
+
+// WithoutBody tells the bind hook to ignore the body, for routes like GET or DELETE which expect none.
+func WithoutBody() Option {
+	return func(c *Configuration) {
+		c.NoBody = true
+	}
+}
+
+// WithCompression enables the compression of the responses with gzip or deflate, negotiated from the
+// Accept-Encoding header. Use a zero hook.Compression for the default minimum size and content types.
+func WithCompression(compression hook.Compression) Option {
+	return func(c *Configuration) {
+		c.Compression = &compression
+	}
+}
+
+// WithoutCompression disables the compression of the responses, for instance on a route serving data already
+// compressed.
+func WithoutCompression() Option {
+	return func(c *Configuration) {
+		c.Compression = nil
+	}
+}
+
+// WithETag sets the ETag header of the responses from their body, a GET or HEAD request with a matching
+// If-None-Match header gets a 304 Not Modified response. The outputs implementing hook.ETagger always have an ETag.
+func WithETag(etag bool) Option {
+	return func(c *Configuration) {
+		c.ETag = etag
+	}
+}
+
+// WithKindStatus overrides the http status code the error hook sends for an error kind.
+func WithKindStatus(kind errors.Kind, statusCode int) Option {
+	return func(c *Configuration) {
+		if c.KindStatus == nil {
+			c.KindStatus = map[errors.Kind]int{}
+		}
+
+		c.KindStatus[kind] = statusCode
+	}
+}
+
+// WithTimeout sets the maximum duration of a handler.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Configuration) {
+		c.Timeout = d
+	}
+}
+
+// WithVerbose sets the verbose mode, which sends the source of each field of the input of a request to the trace
+// hook.
+func WithVerbose(verbose bool) Option {
+	return func(c *Configuration) {
+		c.Verbose = verbose
+	}
 }
 
 func (c Configuration) stringsTags() []string {
@@ -52,3 +375,15 @@ func (c Configuration) valuesTags() []string {
 
 	return append(tags, "default")
 }
+
+func (c Configuration) routeOptions() hook.RouteOptions {
+	return hook.RouteOptions{
+		Codecs:       c.Codecs,
+		Strict:       c.Strict,
+		NoBody:       c.NoBody,
+		Compression:  c.Compression,
+		ETag:         c.ETag,
+		MaxBodyBytes: c.MaxBodyBytes,
+		KindStatus:   c.KindStatus,
+	}
+}
@@ -0,0 +1,346 @@
+package kcd_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/errors"
+	"github.com/alexisvisco/kcd/pkg/extractor"
+	"github.com/alexisvisco/kcd/pkg/hook"
+)
+
+type engineInput struct {
+	Name string `query:"name" header:"name"`
+}
+
+type engineOutput struct {
+	Name string `json:"name"`
+}
+
+func engineHandler(in *engineInput) (engineOutput, error) {
+	if in.Name == "" {
+		return engineOutput{}, errors.NewWithKind(errors.KindNotFound, "no name")
+	}
+
+	return engineOutput{Name: in.Name}, nil
+}
+
+func engineTypedHandler(_ context.Context, in *engineInput) (engineOutput, error) {
+	return engineHandler(in)
+}
+
+func teapotErrorHook(w http.ResponseWriter, _ *http.Request, _ error, _ hook.LogHook) {
+	w.WriteHeader(http.StatusTeapot)
+}
+
+func TestEngine(t *testing.T) {
+	public := kcd.New()
+	admin := kcd.New(
+		kcd.WithErrorHook(teapotErrorHook),
+		kcd.WithStringsExtractors(extractor.Query{}),
+	)
+
+	r := chi.NewRouter()
+	r.Get("/public", public.Handler(engineHandler, http.StatusOK))
+	r.Get("/admin", admin.Handler(engineHandler, http.StatusOK))
+	r.Get("/admin/typed", kcd.HandleOn(admin, engineTypedHandler, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should use the default configuration", func(t *testing.T) {
+		e.GET("/public").WithHeader("name", ValString).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal(ValString)
+
+		e.GET("/public").Expect().
+			Status(http.StatusNotFound).
+			JSON().Path("$.error_description").Equal("no name")
+	})
+
+	t.Run("it should use the configuration of the engine", func(t *testing.T) {
+		e.GET("/admin").WithQuery("name", ValString).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal(ValString)
+
+		e.GET("/admin").WithHeader("name", ValString).Expect().
+			Status(http.StatusTeapot)
+
+		e.GET("/admin/typed").WithHeader("name", ValString).Expect().
+			Status(http.StatusTeapot)
+	})
+}
+
+func TestConfigIsReadAtEachRequest(t *testing.T) {
+	previous := kcd.Config
+	defer func() { kcd.Config = previous }()
+
+	r := chi.NewRouter()
+	r.Get("/", kcd.Handler(engineHandler, http.StatusOK))
+	r.Get("/gone", kcd.Handler(engineHandler, http.StatusOK, kcd.WithKindStatus(errors.KindNotFound, http.StatusGone)))
+	r.Get("/typed", kcd.Handle(engineTypedHandler, http.StatusOK))
+	r.Get("/engine", kcd.New().Handler(engineHandler, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	e.GET("/").Expect().Status(http.StatusNotFound)
+
+	kcd.Config.ErrorHook = teapotErrorHook
+
+	e.GET("/").Expect().Status(http.StatusTeapot)
+	e.GET("/gone").Expect().Status(http.StatusTeapot)
+	e.GET("/typed").Expect().Status(http.StatusTeapot)
+	e.GET("/engine").Expect().Status(http.StatusNotFound)
+}
+
+type routeOptionsInput struct {
+	Name string `json:"name"`
+}
+
+func routeOptionsHandler(in *routeOptionsInput) (routeOptionsInput, error) {
+	return *in, nil
+}
+
+func routeOptionsTimeoutHandler(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRouteOptions(t *testing.T) {
+	engine := kcd.New(kcd.WithMaxBodyBytes(16))
+
+	r := chi.NewRouter()
+	r.Post("/small", engine.Handler(routeOptionsHandler, http.StatusOK))
+	r.Post("/large", engine.Handler(routeOptionsHandler, http.StatusOK, kcd.WithMaxBodyBytes(1024)))
+	r.Get("/gone", engine.Handler(engineHandler, http.StatusOK, kcd.WithKindStatus(errors.KindNotFound, http.StatusGone)))
+	r.Get("/teapot", kcd.Handler(engineHandler, http.StatusOK, kcd.WithErrorHook(teapotErrorHook)))
+	r.Get("/timeout", kcd.Handler(routeOptionsTimeoutHandler, http.StatusOK, kcd.WithTimeout(10*time.Millisecond)))
+	r.Get("/not-found", engine.Handler(engineHandler, http.StatusOK))
+	r.Get("/unprocessable", engine.Handler(strictHandler, http.StatusOK,
+		kcd.WithKindStatus(errors.KindInvalidArgument, http.StatusUnprocessableEntity)))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	body := routeOptionsInput{Name: strings.Repeat("a", 64)}
+
+	t.Run("it should use the body limit of the engine", func(t *testing.T) {
+		e.POST("/small").WithJSON(body).Expect().
+			Status(http.StatusRequestEntityTooLarge).
+			JSON().Path("$.error").Equal(errors.KindRequestEntityTooLarge)
+	})
+
+	t.Run("it should use the body limit of the route", func(t *testing.T) {
+		e.POST("/large").WithJSON(body).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal(body.Name)
+	})
+
+	t.Run("it should use the status of the route for a kind", func(t *testing.T) {
+		e.GET("/gone").Expect().Status(http.StatusGone)
+		e.GET("/not-found").Expect().Status(http.StatusNotFound)
+	})
+
+	t.Run("it should use the status of the route for an invalid input", func(t *testing.T) {
+		e.GET("/unprocessable").WithQuery("limit", "abc").Expect().
+			Status(http.StatusUnprocessableEntity).
+			JSON().Object().
+			ValueEqual("error", errors.KindInvalidArgument).
+			ValueEqual("error_description", http.StatusText(http.StatusUnprocessableEntity))
+	})
+
+	t.Run("it should use the error hook of the route", func(t *testing.T) {
+		e.GET("/teapot").Expect().Status(http.StatusTeapot)
+	})
+
+	t.Run("it should cancel the handler after the timeout", func(t *testing.T) {
+		e.GET("/timeout").Expect().
+			Status(http.StatusRequestTimeout).
+			JSON().Path("$.error").Equal(errors.KindDeadlineExceeded)
+	})
+}
+
+type strictInput struct {
+	Limit  int `query:"limit"`
+	Filter struct {
+		Name string `query:"name"`
+	} `query:"filter"`
+	Email string `json:"email"`
+}
+
+func strictHandler(in *strictInput) (*strictInput, error) {
+	return in, nil
+}
+
+func TestStrict(t *testing.T) {
+	engine := kcd.New(kcd.WithStrict(true))
+
+	r := chi.NewRouter()
+	r.Post("/strict", engine.Handler(strictHandler, http.StatusOK))
+	r.Post("/lax", engine.Handler(strictHandler, http.StatusOK, kcd.WithStrict(false)))
+	r.Post("/default", kcd.Handler(strictHandler, http.StatusOK))
+	r.Post("/route", kcd.Handler(strictHandler, http.StatusOK, kcd.WithStrict(true)))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should accept known parameters", func(t *testing.T) {
+		e.POST("/strict").
+			WithQuery("limit", 10).
+			WithQuery("filter.name", "kcd").
+			WithJSON(map[string]string{"email": "kcd@example.com"}).
+			Expect().Status(http.StatusOK).
+			JSON().Path("$.Filter.Name").Equal("kcd")
+	})
+
+	t.Run("it should reject unknown query parameters", func(t *testing.T) {
+		json := e.POST("/strict").
+			WithQuery("limt", 10).
+			WithQuery("name", "kcd").
+			Expect().Status(http.StatusBadRequest).
+			JSON()
+
+		json.Path("$.error_description").Equal("the request has unknown parameters")
+		json.Path("$.fields").Equal(map[string]string{"limt": "unknown parameter", "name": "unknown parameter"})
+	})
+
+	t.Run("it should reject unknown json fields", func(t *testing.T) {
+		e.POST("/route").
+			WithJSON(map[string]string{"emial": "kcd@example.com"}).
+			Expect().Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Equal(map[string]string{"emial": "unknown parameter"})
+	})
+
+	t.Run("it should reject unknown nested json fields by their path", func(t *testing.T) {
+		e.POST("/strict").
+			WithJSON(map[string]interface{}{
+				"filter": map[string]interface{}{"nme": map[string]string{"first": "kcd"}},
+			}).
+			Expect().Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Equal(map[string]string{"filter.nme": "unknown parameter"})
+	})
+
+	t.Run("it should report the unknown parameters with the invalid fields", func(t *testing.T) {
+		json := e.POST("/strict").
+			WithQuery("limt", 10).
+			WithQuery("limit", "ten").
+			WithJSON(map[string]string{"emial": "kcd@example.com"}).
+			Expect().Status(http.StatusBadRequest).
+			JSON()
+
+		json.Path("$.error_description").Equal("the request has one or multiple invalid fields")
+		json.Path("$.fields").Equal(map[string]string{
+			"limt":  "unknown parameter",
+			"emial": "unknown parameter",
+			"limit": "invalid integer",
+		})
+	})
+
+	t.Run("it should ignore unknown parameters when not strict", func(t *testing.T) {
+		for _, path := range []string{"/lax", "/default"} {
+			e.POST(path).
+				WithQuery("limt", 10).
+				WithJSON(map[string]string{"emial": "kcd@example.com"}).
+				Expect().Status(http.StatusOK)
+		}
+	})
+}
+
+type sourceInput struct {
+	Version string `header:"X-Version" query:"version" from:"query,header,default" default:"v1" json:"version"`
+	Locale  string `header:"Accept-Language" query:"locale" json:"locale"`
+}
+
+func sourceHandler(in *sourceInput) (*sourceInput, error) {
+	return in, nil
+}
+
+func TestSourceOrder(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/", kcd.Handler(sourceHandler, http.StatusOK))
+	r.Post("/body", kcd.Handler(sourceHandler, http.StatusOK, kcd.WithSourceOrder("json", "query")))
+	r.Post("/ambiguous", kcd.Handler(sourceHandler, http.StatusOK, kcd.WithRejectAmbiguous(true)))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should use the order of the from tag", func(t *testing.T) {
+		e.POST("/").WithHeader("X-Version", "v2").WithQuery("version", "v3").Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.version").Equal("v3")
+
+		e.POST("/").WithHeader("X-Version", "v2").Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.version").Equal("v2")
+
+		e.POST("/").Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.version").Equal("v1")
+	})
+
+	t.Run("it should use the order of the extractors by default", func(t *testing.T) {
+		e.POST("/").WithHeader("Accept-Language", "fr").WithQuery("locale", "en").
+			WithJSON(map[string]string{"locale": "de"}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.locale").Equal("fr")
+	})
+
+	t.Run("it should use the order of the configuration", func(t *testing.T) {
+		e.POST("/body").WithQuery("locale", "en").WithJSON(map[string]string{"locale": "de"}).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.locale").Equal("de")
+
+		e.POST("/body").WithHeader("Accept-Language", "fr").WithQuery("locale", "en").Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.locale").Equal("en")
+	})
+
+	t.Run("it should reject a field supplied by two sources", func(t *testing.T) {
+		json := e.POST("/ambiguous").WithQuery("locale", "en").WithJSON(map[string]string{"locale": "de"}).Expect().
+			Status(http.StatusBadRequest).
+			JSON()
+
+		json.Path("$.fields").Equal(map[string]string{"locale": "ambiguous value from query and json"})
+
+		e.POST("/ambiguous").WithHeader("X-Version", "v2").WithQuery("version", "v3").Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields.version").Equal("ambiguous value from query and header")
+	})
+
+	t.Run("it should accept a field supplied by one source and its default", func(t *testing.T) {
+		e.POST("/ambiguous").WithHeader("X-Version", "v2").WithJSON(map[string]string{"locale": "de"}).Expect().
+			Status(http.StatusOK).
+			JSON().Object().ValueEqual("version", "v2").ValueEqual("locale", "de")
+	})
+
+	t.Run("it should panic with an unknown source", func(t *testing.T) {
+		assert.Panics(t, func() {
+			kcd.Handler(func(*struct {
+				Name string `query:"name" from:"quer"`
+			}) error {
+				return nil
+			}, http.StatusOK)
+		})
+	})
+}
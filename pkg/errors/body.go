@@ -0,0 +1,9 @@
+package errors
+
+// BodyTooLarge returns the error of a request body larger than limit, a KindRequestEntityTooLarge error.
+// It is returned by the bind hook and by the form and file extractors.
+func BodyTooLarge(err error, limit int64) *Error {
+	return Wrap(err, "the request body is too large (max %d bytes)", limit).
+		WithKind(KindRequestEntityTooLarge).
+		WithField("limit", limit)
+}
@@ -70,6 +70,15 @@ const (
 	// supported/enabled in this service.
 	KindUnimplemented Kind = "unimplemented"
 
+	// KindNotAcceptable indicates the server is not able to produce a response in a format accepted by the client.
+	KindNotAcceptable Kind = "not_acceptable"
+
+	// KindRequestEntityTooLarge indicates the request body is larger than the limit of the server.
+	KindRequestEntityTooLarge Kind = "request_entity_too_large"
+
+	// KindUnsupportedMediaType indicates the server is not able to read the format of the request body.
+	KindUnsupportedMediaType Kind = "unsupported_media_type"
+
 	// KindInternal errors. When some invariants expected by the underlying system
 	// have been broken. In other words, something bad happened in the library or
 	// backend service. Do not confuse with HTTP Internal Server Error; an
@@ -121,6 +130,12 @@ func (k Kind) ToStatusCode() int {
 		return http.StatusBadRequest
 	case KindUnimplemented:
 		return http.StatusNotImplemented
+	case KindNotAcceptable:
+		return http.StatusNotAcceptable
+	case KindRequestEntityTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case KindUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
 	case KindInternal, KindDataLoss, KindNone:
 		return http.StatusInternalServerError
 	case KindUnavailable:
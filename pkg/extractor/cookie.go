@@ -0,0 +1,165 @@
+package extractor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alexisvisco/kcd/internal/kcderr"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// Cookie allows to obtain a value from the cookies of the request.
+//
+// The value of the tag is the name of the cookie, optionally followed by "signed" or "encrypted":
+//
+//	Session string `cookie:"session,encrypted"`
+//
+// A signed cookie is verified with the HashKey, an encrypted cookie is decrypted with the BlockKey.
+// Use Sign and Encrypt to create the values of these cookies.
+type Cookie struct {
+	// HashKey is the key of the HMAC-SHA256 signature of the signed cookies.
+	HashKey []byte
+
+	// BlockKey is the AES key of the encrypted cookies, it must be 16, 24 or 32 bytes long.
+	BlockKey []byte
+}
+
+// Extract the value of a cookie from the http request.
+func (c Cookie) Extract(req *http.Request, _ http.ResponseWriter, valueOfTag string) ([]string, error) {
+	name, option := valueOfTag, ""
+	if i := strings.Index(valueOfTag, ","); i >= 0 {
+		name, option = valueOfTag[:i], strings.TrimSpace(valueOfTag[i+1:])
+	}
+
+	cookie, err := req.Cookie(name)
+	if err != nil {
+		return nil, nil
+	}
+
+	var (
+		value     = cookie.Value
+		decodeErr *errors.Error
+	)
+
+	switch option {
+	case "":
+	case "signed":
+		value, decodeErr = c.verify(name, value)
+	case "encrypted":
+		value, decodeErr = c.decrypt(name, value)
+	default:
+		decodeErr = errors.NewWithKind(kcderr.InputCritical, "unknown cookie option %s", option)
+	}
+
+	if decodeErr != nil {
+		return nil, decodeErr.
+			WithField("decoding-strategy", "cookie").
+			WithField("path", name)
+	}
+
+	return []string{value}, nil
+}
+
+// Tag return the tag name of this extractor.
+func (c Cookie) Tag() string {
+	return "cookie"
+}
+
+// Sign returns the signed value of the cookie name, it is verified by a "signed" cookie tag.
+func (c Cookie) Sign(name, value string) (string, error) {
+	if len(c.HashKey) == 0 {
+		return "", errors.NewWithKind(errors.KindInternal, "no hash key to sign cookies")
+	}
+
+	return encode([]byte(value)) + "." + encode(c.mac(name, []byte(value))), nil
+}
+
+// Encrypt returns the encrypted value of the cookie name, it is decrypted by an "encrypted" cookie tag.
+func (c Cookie) Encrypt(name, value string) (string, error) {
+	aead, err := c.aead()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to encrypt cookie").WithKind(errors.KindInternal)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "unable to encrypt cookie").WithKind(errors.KindInternal)
+	}
+
+	return encode(aead.Seal(nonce, nonce, []byte(value), []byte(name))), nil
+}
+
+func (c Cookie) verify(name, signed string) (string, *errors.Error) {
+	if len(c.HashKey) == 0 {
+		return "", errors.NewWithKind(kcderr.InputCritical, "no hash key to verify signed cookies")
+	}
+
+	parts := strings.Split(signed, ".")
+	if len(parts) != 2 {
+		return "", errors.NewWithKind(kcderr.Input, "invalid signed cookie")
+	}
+
+	value, errValue := decode(parts[0])
+	signature, errSignature := decode(parts[1])
+
+	if errValue != nil || errSignature != nil || !hmac.Equal(signature, c.mac(name, value)) {
+		return "", errors.NewWithKind(kcderr.Input, "invalid signed cookie")
+	}
+
+	return string(value), nil
+}
+
+func (c Cookie) decrypt(name, encrypted string) (string, *errors.Error) {
+	aead, err := c.aead()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to decrypt cookies").WithKind(kcderr.InputCritical)
+	}
+
+	data, err := decode(encrypted)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", errors.NewWithKind(kcderr.Input, "invalid encrypted cookie")
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	value, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", errors.NewWithKind(kcderr.Input, "invalid encrypted cookie")
+	}
+
+	return string(value), nil
+}
+
+// mac returns the signature of the value, the name of the cookie is signed to prevent swapping cookies.
+func (c Cookie) mac(name string, value []byte) []byte {
+	h := hmac.New(sha256.New, c.HashKey)
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(value)
+
+	return h.Sum(nil)
+}
+
+func (c Cookie) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.BlockKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
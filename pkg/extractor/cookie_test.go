@@ -0,0 +1,113 @@
+package extractor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/extractor"
+)
+
+type cookieRequest struct {
+	Theme   string `cookie:"theme"`
+	UserID  int    `cookie:"user,signed"`
+	Session string `cookie:"session,encrypted"`
+}
+
+type cookieResponse struct {
+	Theme   string `json:"theme"`
+	UserID  int    `json:"user_id"`
+	Session string `json:"session"`
+}
+
+func TestCookieExtractor(t *testing.T) {
+	cookies := extractor.Cookie{HashKey: []byte("hash-key"), BlockKey: []byte("0123456789abcdef")}
+
+	r := chi.NewRouter()
+	r.Get("/", kcd.New(kcd.WithCookieKeys(cookies.HashKey, cookies.BlockKey)).
+		Handler(func(req *cookieRequest) (cookieResponse, error) {
+			return cookieResponse(*req), nil
+		}, 200))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	signed, err := cookies.Sign("user", "42")
+	require.NoError(t, err)
+
+	encrypted, err := cookies.Encrypt("session", "secret")
+	require.NoError(t, err)
+
+	t.Run("it should bind the cookies", func(t *testing.T) {
+		json := e.GET("/").
+			WithCookie("theme", "dark").
+			WithCookie("user", signed).
+			WithCookie("session", encrypted).
+			Expect().Status(200).JSON()
+
+		json.Path("$.theme").Equal("dark")
+		json.Path("$.user_id").Equal(42)
+		json.Path("$.session").Equal("secret")
+	})
+
+	t.Run("it should leave missing cookies empty", func(t *testing.T) {
+		json := e.GET("/").Expect().Status(200).JSON()
+
+		json.Path("$.theme").Equal("")
+		json.Path("$.user_id").Equal(0)
+	})
+
+	t.Run("it should reject a tampered signed cookie", func(t *testing.T) {
+		// "MQ" is the value "1" with the signature of the value "42".
+		e.GET("/").
+			WithCookie("user", "MQ"+signed[strings.Index(signed, "."):]).
+			Expect().Status(400).
+			JSON().Path("$.fields.user").Equal("invalid signed cookie")
+	})
+
+	t.Run("it should reject a signed cookie of another name", func(t *testing.T) {
+		other, err := cookies.Sign("admin", "42")
+		require.NoError(t, err)
+
+		e.GET("/").
+			WithCookie("user", other).
+			Expect().Status(400).
+			JSON().Path("$.fields.user").Equal("invalid signed cookie")
+	})
+
+	t.Run("it should reject a tampered encrypted cookie", func(t *testing.T) {
+		e.GET("/").
+			WithCookie("session", tamper(encrypted)).
+			Expect().Status(400).
+			JSON().Path("$.fields.session").Equal("invalid encrypted cookie")
+	})
+
+	t.Run("it should fail without keys", func(t *testing.T) {
+		r := chi.NewRouter()
+		r.Get("/", kcd.New().Handler(func(req *cookieRequest) error { return nil }, 200))
+
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		httpexpect.New(t, server.URL).GET("/").
+			WithCookie("user", signed).
+			Expect().Status(http.StatusInternalServerError)
+	})
+}
+
+// tamper changes the first character of a base64 value.
+func tamper(s string) string {
+	if s[0] == 'A' {
+		return "B" + s[1:]
+	}
+
+	return "A" + s[1:]
+}
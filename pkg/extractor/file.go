@@ -0,0 +1,34 @@
+package extractor
+
+import (
+	"net/http"
+)
+
+// File allows to obtain the files of a multipart/form-data body.
+//
+// The field may be a *multipart.FileHeader, a []*multipart.FileHeader or an io.ReadCloser.
+// An io.ReadCloser is the opened file, the handler must close it.
+type File struct {
+	// MaxMemory is the maximum number of bytes stored in memory, DefaultMaxMemory is used if zero.
+	MaxMemory int64
+}
+
+// Extract the files of a field of the multipart form, as a []*multipart.FileHeader.
+func (f File) Extract(req *http.Request, _ http.ResponseWriter, valueOfTag string) (interface{}, error) {
+	ok, err := parseMultipartForm(req, f.MaxMemory)
+	if !ok || err != nil {
+		return nil, err
+	}
+
+	files := req.MultipartForm.File[valueOfTag]
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	return files, nil
+}
+
+// Tag return the tag name of this extractor.
+func (f File) Tag() string {
+	return "file"
+}
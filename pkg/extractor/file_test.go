@@ -0,0 +1,124 @@
+package extractor_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexisvisco/kcd"
+)
+
+type fileRequest struct {
+	Avatar      *multipart.FileHeader   `file:"avatar" maxsize:"16B" accept:"image/*"`
+	Attachments []*multipart.FileHeader `file:"attachments" accept:"text/plain,application/pdf"`
+	Content     io.ReadCloser           `file:"content"`
+}
+
+type fileResponse struct {
+	Avatar      string   `json:"avatar"`
+	Attachments []string `json:"attachments"`
+	Content     string   `json:"content"`
+}
+
+type uploadedFile struct {
+	field, filename, contentType, content string
+}
+
+// multipartBody returns a multipart body with the files and its content type.
+func multipartBody(t *testing.T, files ...uploadedFile) ([]byte, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for _, file := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+file.field+`"; filename="`+file.filename+`"`)
+		header.Set("Content-Type", file.contentType)
+
+		part, err := writer.CreatePart(header)
+		require.NoError(t, err)
+
+		_, err = part.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, writer.Close())
+
+	return body.Bytes(), writer.FormDataContentType()
+}
+
+func TestFileExtractor(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/", kcd.Handler(func(req *fileRequest) (fileResponse, error) {
+		res := fileResponse{}
+
+		if req.Avatar != nil {
+			res.Avatar = req.Avatar.Filename
+		}
+
+		for _, attachment := range req.Attachments {
+			res.Attachments = append(res.Attachments, attachment.Filename)
+		}
+
+		if req.Content != nil {
+			defer req.Content.Close()
+
+			content, err := io.ReadAll(req.Content)
+			if err != nil {
+				return res, err
+			}
+
+			res.Content = string(content)
+		}
+
+		return res, nil
+	}, 200))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should bind the files", func(t *testing.T) {
+		body, contentType := multipartBody(t,
+			uploadedFile{"avatar", "avatar.png", "image/png", "png"},
+			uploadedFile{"attachments", "a.txt", "text/plain", "a"},
+			uploadedFile{"attachments", "b.pdf", "application/pdf", "b"},
+			uploadedFile{"content", "content.txt", "text/plain", "hello"},
+		)
+
+		json := e.POST("/").WithBytes(body).WithHeader("Content-Type", contentType).
+			Expect().Status(200).JSON()
+
+		json.Path("$.avatar").Equal("avatar.png")
+		json.Path("$.attachments").Equal([]string{"a.txt", "b.pdf"})
+		json.Path("$.content").Equal("hello")
+	})
+
+	t.Run("it should reject a file too large", func(t *testing.T) {
+		body, contentType := multipartBody(t,
+			uploadedFile{"avatar", "avatar.png", "image/png", "a picture larger than 16 bytes"},
+		)
+
+		e.POST("/").WithBytes(body).WithHeader("Content-Type", contentType).
+			Expect().Status(400).
+			JSON().Path("$.fields.avatar").Equal("file is too large (max 16B)")
+	})
+
+	t.Run("it should reject a file with an unsupported content type", func(t *testing.T) {
+		body, contentType := multipartBody(t,
+			uploadedFile{"attachments", "a.txt", "text/plain", "a"},
+			uploadedFile{"attachments", "b.html", "text/html", "b"},
+		)
+
+		e.POST("/").WithBytes(body).WithHeader("Content-Type", contentType).
+			Expect().Status(400).
+			JSON().Path("$.fields.attachments").Equal(`unsupported content type "text/html"`)
+	})
+}
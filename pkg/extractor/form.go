@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	goerrors "errors"
+	"mime"
+	"net/http"
+
+	"github.com/alexisvisco/kcd/internal/kcderr"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// DefaultMaxMemory is the maximum number of bytes of a multipart form stored in memory,
+// the remaining is stored in temporary files.
+const DefaultMaxMemory = 32 << 20
+
+// Form allows to obtain a value from an application/x-www-form-urlencoded or a multipart/form-data body.
+type Form struct {
+	// MaxMemory is the maximum number of bytes of a multipart form stored in memory, DefaultMaxMemory is used if zero.
+	MaxMemory int64
+}
+
+// Extract values of a field of the form.
+func (f Form) Extract(req *http.Request, _ http.ResponseWriter, valueOfTag string) ([]string, error) {
+	if err := parseForm(req, f.MaxMemory); err != nil {
+		return nil, err
+	}
+
+	return req.PostForm[valueOfTag], nil
+}
+
+// Tag return the tag name of this extractor.
+func (f Form) Tag() string {
+	return "form"
+}
+
+// parseForm parses the url-encoded or the multipart form once, the values are in req.PostForm.
+func parseForm(req *http.Request, maxMemory int64) error {
+	ok, err := parseMultipartForm(req, maxMemory)
+	if ok || err != nil {
+		return err
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return formError(err, "unable to read form")
+	}
+
+	return nil
+}
+
+// parseMultipartForm parses the multipart form once, ok is false if the body is not a multipart form.
+func parseMultipartForm(req *http.Request, maxMemory int64) (ok bool, err error) {
+	if req.MultipartForm != nil {
+		return true, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return false, nil
+	}
+
+	if maxMemory <= 0 {
+		maxMemory = DefaultMaxMemory
+	}
+
+	if err := req.ParseMultipartForm(maxMemory); err != nil {
+		return false, formError(err, "unable to read multipart form")
+	}
+
+	return true, nil
+}
+
+// formError returns the error of the parsing of a form, a body larger than the limit of the bind hook is
+// reported as such.
+func formError(err error, message string) error {
+	var maxBytesErr *http.MaxBytesError
+	if goerrors.As(err, &maxBytesErr) {
+		return errors.BodyTooLarge(err, maxBytesErr.Limit)
+	}
+
+	return errors.Wrap(err, message).
+		WithKind(kcderr.Input).
+		WithField("decoding-strategy", "form")
+}
@@ -0,0 +1,93 @@
+package extractor_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+
+	"github.com/alexisvisco/kcd"
+)
+
+type formRequest struct {
+	Name string   `form:"name"`
+	Age  int      `form:"age"`
+	Tags []string `form:"tags"`
+	IDs  []string `form:"ids" exploder:","`
+	Sort string   `form:"sort" default:"asc"`
+}
+
+type formResponse struct {
+	Name string   `json:"name"`
+	Age  int      `json:"age"`
+	Tags []string `json:"tags"`
+	IDs  []string `json:"ids"`
+	Sort string   `json:"sort"`
+}
+
+func TestFormExtractor(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/", kcd.Handler(func(req *formRequest) (formResponse, error) {
+		return formResponse(*req), nil
+	}, 200))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should bind the fields of the multipart form", func(t *testing.T) {
+		json := e.POST("/").
+			WithMultipart().
+			WithFormField("name", "kcd").
+			WithFormField("age", 3).
+			WithFormField("tags", "a").
+			WithFormField("tags", "b").
+			Expect().Status(200).JSON()
+
+		json.Path("$.name").Equal("kcd")
+		json.Path("$.age").Equal(3)
+		json.Path("$.tags").Equal([]string{"a", "b"})
+		json.Path("$.sort").Equal("asc")
+	})
+
+	t.Run("it should bind the fields of the url-encoded form", func(t *testing.T) {
+		json := e.POST("/").
+			WithFormField("name", "kcd").
+			WithFormField("age", 3).
+			WithFormField("tags", "a").
+			WithFormField("tags", "b").
+			WithFormField("ids", "1,2,3").
+			Expect().Status(200).JSON()
+
+		json.Path("$.name").Equal("kcd")
+		json.Path("$.age").Equal(3)
+		json.Path("$.tags").Equal([]string{"a", "b"})
+		json.Path("$.ids").Equal([]string{"1", "2", "3"})
+		json.Path("$.sort").Equal("asc")
+	})
+
+	t.Run("it should not bind the query parameters", func(t *testing.T) {
+		e.POST("/").
+			WithQuery("name", "query").
+			WithFormField("age", 3).
+			Expect().Status(200).
+			JSON().Path("$.name").Equal("")
+	})
+
+	t.Run("it should report an invalid field", func(t *testing.T) {
+		e.POST("/").
+			WithFormField("age", "three").
+			Expect().Status(400).
+			JSON().Path("$.fields.age").Equal("invalid integer")
+	})
+
+	t.Run("it should report an invalid field of a multipart form", func(t *testing.T) {
+		e.POST("/").
+			WithMultipart().
+			WithFormField("age", "three").
+			Expect().Status(400).
+			JSON().Path("$.fields.age").Equal("invalid integer")
+	})
+}
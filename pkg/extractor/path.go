@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi"
+	"github.com/gorilla/mux"
+	"github.com/julienschmidt/httprouter"
 )
 
 // Path extract value from the chi router.
@@ -11,15 +13,46 @@ type Path struct{}
 
 // Extract value from the chi router.
 func (p Path) Extract(req *http.Request, _ http.ResponseWriter, valueOfTag string) ([]string, error) {
-	str := chi.URLParam(req, valueOfTag)
-	if str == "" {
-		return nil, nil
-	}
-
-	return []string{str}, nil
+	return pathValue(chi.URLParam(req, valueOfTag))
 }
 
 // Tag return the tag name of this extractor.
 func (p Path) Tag() string {
 	return "path"
 }
+
+// MuxPath extract value from the gorilla/mux router.
+type MuxPath struct{}
+
+// Extract value from the gorilla/mux router.
+func (p MuxPath) Extract(req *http.Request, _ http.ResponseWriter, valueOfTag string) ([]string, error) {
+	return pathValue(mux.Vars(req)[valueOfTag])
+}
+
+// Tag return the tag name of this extractor.
+func (p MuxPath) Tag() string {
+	return "path"
+}
+
+// HTTPRouterPath extract value from the julienschmidt/httprouter router.
+// The handler must be registered with httprouter.Router.Handler or httprouter.Router.HandlerFunc so the
+// parameters are in the context of the request.
+type HTTPRouterPath struct{}
+
+// Extract value from the julienschmidt/httprouter router.
+func (p HTTPRouterPath) Extract(req *http.Request, _ http.ResponseWriter, valueOfTag string) ([]string, error) {
+	return pathValue(httprouter.ParamsFromContext(req.Context()).ByName(valueOfTag))
+}
+
+// Tag return the tag name of this extractor.
+func (p HTTPRouterPath) Tag() string {
+	return "path"
+}
+
+func pathValue(str string) ([]string, error) {
+	if str == "" {
+		return nil, nil
+	}
+
+	return []string{str}, nil
+}
@@ -0,0 +1,19 @@
+//go:build go1.22
+
+package extractor
+
+import "net/http"
+
+// ServeMuxPath extract value from the wildcards of the net/http.ServeMux patterns, available since Go 1.22.
+// The wildcards are only enabled when the go version of the main module is at least 1.22.
+type ServeMuxPath struct{}
+
+// Extract value from the net/http.ServeMux.
+func (p ServeMuxPath) Extract(req *http.Request, _ http.ResponseWriter, valueOfTag string) ([]string, error) {
+	return pathValue(req.PathValue(valueOfTag))
+}
+
+// Tag return the tag name of this extractor.
+func (p ServeMuxPath) Tag() string {
+	return "path"
+}
@@ -0,0 +1,26 @@
+//go:build go1.22
+
+// the patterns of the ServeMux depend on the go version of the main module, which is older than Go 1.22.
+//go:debug httpmuxgo121=0
+
+package extractor_test
+
+import (
+	"net/http"
+
+	"github.com/alexisvisco/kcd/pkg/extractor"
+)
+
+func init() {
+	pathRouters = append(pathRouters, pathRouter{
+		name:      "net/http",
+		extractor: extractor.ServeMuxPath{},
+		parameter: func(name string) string { return "{" + name + "}" },
+		route: func(pattern string, h http.HandlerFunc) http.Handler {
+			r := http.NewServeMux()
+			r.HandleFunc(http.MethodGet+" "+pattern, h)
+
+			return r
+		},
+	})
+}
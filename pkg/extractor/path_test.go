@@ -2,44 +2,103 @@ package extractor_test
 
 import (
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
 
 	"github.com/gavv/httpexpect"
 	"github.com/go-chi/chi"
+	"github.com/gorilla/mux"
+	"github.com/julienschmidt/httprouter"
 
 	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/extractor"
 )
 
+// pathRouter is a router with its path extractor.
+type pathRouter struct {
+	name      string
+	extractor extractor.Strings
+
+	// parameter returns the syntax of a parameter in a pattern.
+	parameter func(name string) string
+	// route returns a router serving the handler on the GET pattern.
+	route func(pattern string, h http.HandlerFunc) http.Handler
+}
+
+var pathRouters = []pathRouter{
+	{
+		name:      "chi",
+		extractor: extractor.Path{},
+		parameter: func(name string) string { return "{" + name + "}" },
+		route: func(pattern string, h http.HandlerFunc) http.Handler {
+			r := chi.NewRouter()
+			r.Get(pattern, h)
+
+			return r
+		},
+	},
+	{
+		name:      "gorilla/mux",
+		extractor: extractor.MuxPath{},
+		parameter: func(name string) string { return "{" + name + "}" },
+		route: func(pattern string, h http.HandlerFunc) http.Handler {
+			r := mux.NewRouter()
+			r.HandleFunc(pattern, h).Methods(http.MethodGet)
+
+			return r
+		},
+	},
+	{
+		name:      "httprouter",
+		extractor: extractor.HTTPRouterPath{},
+		parameter: func(name string) string { return ":" + name },
+		route: func(pattern string, h http.HandlerFunc) http.Handler {
+			r := httprouter.New()
+			r.HandlerFunc(http.MethodGet, pattern, h)
+
+			return r
+		},
+	},
+}
+
 func TestPathExtractor(t *testing.T) {
-	r := chi.NewRouter()
+	for _, router := range pathRouters {
+		router := router
+
+		t.Run(router.name, func(t *testing.T) {
+			testPathExtractor(t, router)
+		})
+	}
+}
 
-	urlChi := ""
+func testPathExtractor(t *testing.T, router pathRouter) {
+	pattern := ""
 	urlRequest := ""
 	for _, assertion := range testArray {
 		if reflect.TypeOf(assertion.value).Kind() == reflect.Slice {
-			// !!!! Currently header does not support slice values
+			// !!!! Currently path does not support slice values
 			continue
 		}
-		urlChi += fmt.Sprintf("/{%s}", assertion.rawKey)
+		pattern += "/" + router.parameter(assertion.rawKey)
 		urlRequest += fmt.Sprintf("/%v", assertion.value)
 	}
 
-	r.Get(urlChi, kcd.Handler(extractorHandler, 200))
+	engine := kcd.New(kcd.WithPathExtractor(router.extractor))
 
-	server := httptest.NewServer(r)
+	server := httptest.NewServer(router.route(pattern, engine.Handler(extractorHandler, 200)))
 	defer server.Close()
 
 	e := httpexpect.New(t, server.URL)
 
 	request := e.GET(urlRequest)
 
-	jsonExpect := request.Expect().JSON()
+	jsonExpect := request.Expect().Status(200).JSON()
 
 	for _, assertion := range testArray {
 		if reflect.TypeOf(assertion.value).Kind() == reflect.Slice {
-			// !!!! Currently header does not support slice values
+			// !!!! Currently path does not support slice values
 			continue
 		}
 
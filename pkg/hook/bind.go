@@ -1,9 +1,12 @@
 package hook
 
 import (
-	"encoding/json"
+	goerrors "errors"
 	"io"
+	"mime"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/alexisvisco/kcd/pkg/errors"
@@ -11,28 +14,135 @@ import (
 	"github.com/alexisvisco/kcd/internal/kcderr"
 )
 
-// Bind returns a Bind hook, it will read only maxBodyBytes bytes from the body and unmarshall
-// the input interface with the json encoding of the stdlib.
+// Bind returns a Bind hook, it decodes the body into the input with the codec of its Content-Type, see
+// CodecsFromContext. The body is decoded while it is read and it is limited to maxBodyBytes bytes, a larger
+// body is rejected with a KindRequestEntityTooLarge error, see errors.BodyTooLarge.
+// The MaxBodyBytes of the route options overrides maxBodyBytes, the body is ignored if the route options have
+// NoBody set. In strict mode, the unknown fields are rejected by the codecs implementing StrictCodec, unless
+// the fields of the body are recorded in BodyFields: kcd then reports them with the errors of the other fields.
+//
+// A body with a Content-Type without codec is rejected with a KindUnsupportedMediaType error, a body without
+// Content-Type is decoded as json.
+// A body encoded with gzip, deflate or br is decompressed and the limit applies to the decompressed body,
+// the other encodings are rejected with a KindUnsupportedMediaType error.
+// A multipart/form-data or an application/x-www-form-urlencoded body is only limited, it is read by the form
+// and file extractors.
+//
+// The fields present in a JSON body are recorded in the BodyFields of the request context, if any.
 func Bind(maxBodyBytes int64) BindHook {
 	return func(w http.ResponseWriter, r *http.Request, in interface{}) error {
-		if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
-			if r.ContentLength == 0 {
-				return nil
-			}
-			r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		options := RouteOptionsFromContext(r.Context())
+		if options.NoBody || r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+			return nil
+		}
 
-			bytesBody, err := io.ReadAll(r.Body)
-			if err != nil {
-				return errors.Wrap(err, "unable to read body").WithKind(kcderr.InputCritical)
-			}
+		limit := maxBodyBytes
+		if options.MaxBodyBytes > 0 {
+			limit = options.MaxBodyBytes
+		}
+
+		contentType := r.Header.Get("Content-Type")
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if contentType == "" {
+			mediaType = JSONCodec{}.MediaType()
+		}
+		isForm := mediaType == "multipart/form-data" || mediaType == "application/x-www-form-urlencoded"
+
+		codec, ok := CodecsFromContext(r.Context()).Lookup(mediaType)
+		if !ok && !isForm {
+			return errors.NewWithKind(errors.KindUnsupportedMediaType, "unsupported content type %q", contentType).
+				WithField("content-type", contentType)
+		}
+
+		if err := decompress(r); err != nil {
+			return err
+		}
+
+		// the limit applies to the decompressed body.
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+		if isForm {
+			// forms are read by the form and file extractors, they need no codec.
+			return nil
+		}
+
+		var (
+			body    io.Reader = r.Body
+			scanner *jsonScanner
+			fields  = BodyFieldsFromContext(r.Context())
+			track   = fields != nil && isJSON(mediaType)
+		)
+
+		// the scanner records the fields of the body and finds the path of the value at fault of a type error while
+		// the body is decoded.
+		if isJSON(mediaType) {
+			scanner = newJSONScanner(r.Body, reflect.TypeOf(in), track)
+			body = scanner
+		}
+
+		// the unknown fields of a tracked body are reported by kcd with their full path, with the other errors.
+		decode := codec.Decode
+		if strict, ok := codec.(StrictCodec); ok && options.Strict && !track {
+			decode = strict.DecodeStrict
+		}
+
+		err := decode(body, in)
 
-			if err := json.Unmarshal(bytesBody, in); err != nil {
-				return errors.Wrap(err, "unable to read json request").
-					WithKind(kcderr.Input).
-					WithField("decoding-strategy", "json")
+		var maxBytesErr *http.MaxBytesError
+
+		switch {
+		case err == nil:
+			if track {
+				fields.Add(scanner.paths...)
 			}
+
+			return nil
+		case goerrors.Is(err, io.EOF):
+			// io.EOF is returned for a body of unknown length which is empty.
+			return nil
+		case goerrors.As(err, &maxBytesErr):
+			return errors.BodyTooLarge(err, maxBytesErr.Limit)
 		}
 
-		return nil
+		if scanner != nil {
+			err = scanner.withPath(err)
+		}
+
+		return decodeError(err, codec)
+	}
+}
+
+func decodeError(err error, codec Codec) error {
+	if field, ok := unknownJSONField(err); ok {
+		return errors.Wrap(err, "the request has unknown parameters").
+			WithKind(kcderr.Input).
+			WithField("decoding-strategy", "json").
+			WithField("fields", map[string]string{field: "unknown parameter"})
+	}
+
+	if _, ok := codec.(JSONCodec); ok {
+		return jsonError(err)
 	}
+
+	return errors.Wrap(err, "unable to read "+codec.MediaType()+" request").
+		WithKind(kcderr.Input).
+		WithField("decoding-strategy", "body")
+}
+
+// unknownJSONField returns the name of the field of an error of a json decoder disallowing unknown fields.
+func unknownJSONField(err error) (string, bool) {
+	const prefix = "json: unknown field "
+
+	if !strings.HasPrefix(err.Error(), prefix) {
+		return "", false
+	}
+
+	field, unquoteErr := strconv.Unquote(strings.TrimPrefix(err.Error(), prefix))
+
+	return field, unquoteErr == nil
+}
+
+// isJSON returns true if the media type is application/json or a JSON based media type.
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
 }
@@ -3,16 +3,18 @@ package hook_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gavv/httpexpect"
 	"github.com/go-chi/chi"
 
 	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/hook"
 )
 
 type hookBindStruct struct {
-	Name string `json:"name"`
+	Name string `json:"name" xml:"name"`
 }
 
 func hookBindHandler(bindStruct *hookBindStruct) (hookBindStruct, error) {
@@ -21,7 +23,10 @@ func hookBindHandler(bindStruct *hookBindStruct) (hookBindStruct, error) {
 
 func TestBind(t *testing.T) {
 	r := chi.NewRouter()
-	r.Post("/", kcd.Handler(hookBindHandler, 200))
+	r.Post("/", kcd.Handler(hookBindHandler, 200, kcd.WithCodecs(hook.XMLCodec{})))
+	r.Delete("/", kcd.Handler(hookBindHandler, 200, kcd.WithoutBody()))
+	r.Put("/items", kcd.Handler(func(*hookBindItems) error { return nil }, 200))
+	r.Post("/small", kcd.Handler(hookBindHandler, 200, kcd.WithMaxBodyBytes(32)))
 
 	server := httptest.NewServer(r)
 	defer server.Close()
@@ -47,4 +52,160 @@ func TestBind(t *testing.T) {
 			Status(http.StatusOK).
 			JSON().Path("$.name").Equal("")
 	})
+
+	t.Run("it should decode a xml body", func(t *testing.T) {
+		e.POST("/").
+			WithHeader("Content-Type", "application/xml; charset=utf-8").
+			WithBytes([]byte("<hookBindStruct><name>kcd</name></hookBindStruct>")).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal("kcd")
+	})
+
+	t.Run("it should fail because of invalid xml body", func(t *testing.T) {
+		e.POST("/").
+			WithHeader("Content-Type", "application/xml").
+			WithBytes([]byte("<hookBindStruct><name>")).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.error_description").Equal("unable to read application/xml request")
+	})
+
+	t.Run("it should reject an unsupported content type", func(t *testing.T) {
+		json := e.POST("/").
+			WithHeader("Content-Type", "text/csv").
+			WithBytes([]byte("name\nkcd")).Expect().
+			Status(http.StatusUnsupportedMediaType).
+			JSON()
+
+		json.Path("$.error").Equal("unsupported_media_type")
+		json.Path("$.error_description").Equal(`unsupported content type "text/csv"`)
+	})
+
+	t.Run("it should reject an unsupported content type with a percent sign", func(t *testing.T) {
+		e.POST("/").
+			WithHeader("Content-Type", "text/x-%d").
+			WithBytes([]byte("kcd")).Expect().
+			Status(http.StatusUnsupportedMediaType).
+			JSON().Path("$.error_description").Equal(`unsupported content type "text/x-%d"`)
+	})
+
+	t.Run("it should decode a body without content type as json", func(t *testing.T) {
+		e.POST("/").
+			WithBytes([]byte(`{"name": "kcd"}`)).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal("kcd")
+	})
+
+	t.Run("it should ignore the body of a route without body", func(t *testing.T) {
+		e.DELETE("/").
+			WithHeader("Content-Type", "text/csv").
+			WithBytes([]byte("name\nkcd")).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal("")
+	})
+
+	t.Run("it should report the path of an invalid json type", func(t *testing.T) {
+		json := e.PUT("/items").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"items": [{"price": 1}, {"price": 2}, {"price": "3"}]}`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON()
+
+		json.Path("$.error_description").Equal("unable to read json request")
+		json.Path("$.fields").Equal(map[string]string{
+			"items[2].price": "invalid type: expected number, got string (offset 52)",
+		})
+	})
+
+	t.Run("it should report the path of an invalid json type in a map", func(t *testing.T) {
+		e.PUT("/items").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"by_id": {"2": {"price": true}}}`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Equal(map[string]string{
+			"by_id.2.price": "invalid type: expected number, got boolean (offset 30)",
+		})
+	})
+
+	t.Run("it should report the path of an invalid json type in nested arrays", func(t *testing.T) {
+		e.PUT("/items").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"matrix": [[1], [2, "3"]]}`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Equal(map[string]string{
+			"matrix[1][1]": "invalid type: expected number, got string (offset 24)",
+		})
+	})
+
+	t.Run("it should report the path of a json number out of the range of its field", func(t *testing.T) {
+		e.PUT("/items").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"items": [{"price": 1}, {"price": 1e400}]}`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Object().Keys().Equal([]string{"items[1].price"})
+	})
+
+	t.Run("it should report the path of an invalid json type after escaped strings", func(t *testing.T) {
+		e.PUT("/items").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"by_id": {"a\"}": {"price": 1}, "b": {"price": "[\"}"}}}`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Object().Keys().Equal([]string{"by_id.b.price"})
+	})
+
+	t.Run("it should report an invalid json type of the body", func(t *testing.T) {
+		e.PUT("/items").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`[1]`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Equal(map[string]string{
+			"body": "invalid type: expected object, got array (offset 1)",
+		})
+	})
+
+	t.Run("it should report the offset of a json syntax error", func(t *testing.T) {
+		e.PUT("/items").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"items": [1,}`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.fields").Equal(map[string]string{
+			"body": "invalid json: invalid character '}' looking for beginning of value (offset 14)",
+		})
+	})
+
+	t.Run("it should reject a body larger than the limit", func(t *testing.T) {
+		json := e.POST("/small").
+			WithHeader("Content-Type", "application/json").
+			WithChunked(strings.NewReader(`{"name": "` + strings.Repeat("a", 64) + `"}`)).Expect().
+			Status(http.StatusRequestEntityTooLarge).
+			JSON()
+
+		json.Path("$.error").Equal("request_entity_too_large")
+		json.Path("$.error_description").Equal("the request body is too large (max 32 bytes)")
+	})
+
+	t.Run("it should succeed with a body of unknown length", func(t *testing.T) {
+		e.POST("/small").
+			WithHeader("Content-Type", "application/json").
+			WithChunked(strings.NewReader(`{"name": "kcd"}`)).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal("kcd")
+	})
+
+	t.Run("it should reject data after the json value", func(t *testing.T) {
+		e.POST("/").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"name": "kcd"} {}`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.error_description").Equal("unable to read json request")
+	})
+}
+
+type hookBindItem struct {
+	Price float64 `json:"price"`
+}
+
+type hookBindItems struct {
+	Items  []hookBindItem          `json:"items"`
+	ByID   map[string]hookBindItem `json:"by_id"`
+	Matrix [][]int                 `json:"matrix"`
 }
@@ -0,0 +1,77 @@
+package hook
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+type bodyFieldsKey struct{}
+
+// BodyFields are the fields present in the body of a request, by their path of json names like "filter.since".
+// The bind hook records them when the request context has BodyFields, see ContextWithBodyFields, so kcd knows
+// a field comes from the body even if its value is zero. Only the JSON bodies are tracked, their keys are recorded
+// while they are decoded, the keys of the objects in arrays are not. kcd only adds BodyFields to the routes which
+// need them.
+type BodyFields struct {
+	tracked bool
+
+	// paths are the paths as sent by the client by their lower case path.
+	paths map[string]string
+}
+
+// ContextWithBodyFields returns a copy of ctx holding empty BodyFields.
+func ContextWithBodyFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bodyFieldsKey{}, &BodyFields{paths: map[string]string{}})
+}
+
+// BodyFieldsFromContext returns the BodyFields of ctx, or nil if there is none.
+func BodyFieldsFromContext(ctx context.Context) *BodyFields {
+	fields, _ := ctx.Value(bodyFieldsKey{}).(*BodyFields)
+	return fields
+}
+
+// Add marks the fields as present in the body, and the body as tracked.
+func (b *BodyFields) Add(paths ...string) {
+	if b == nil {
+		return
+	}
+
+	b.tracked = true
+
+	for _, path := range paths {
+		b.paths[strings.ToLower(path)] = path
+	}
+}
+
+// Tracked returns true if the fields of the body were recorded.
+func (b *BodyFields) Tracked() bool {
+	return b != nil && b.tracked
+}
+
+// Has returns true if the field is present in the body, the names are compared without case like encoding/json.
+func (b *BodyFields) Has(path string) bool {
+	if b == nil {
+		return false
+	}
+
+	_, ok := b.paths[strings.ToLower(path)]
+
+	return ok
+}
+
+// Paths returns the sorted paths of the fields present in the body, as sent by the client.
+func (b *BodyFields) Paths() []string {
+	if b == nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(b.paths))
+	for _, path := range b.paths {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
@@ -0,0 +1,91 @@
+package hook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/hook"
+)
+
+type hookBodyFieldsInput struct {
+	Name   string `json:"name" default:"anonymous"`
+	Filter struct {
+		Sort string `json:"sort"`
+	} `json:"filter"`
+	Items []struct {
+		Price int `json:"price"`
+	} `json:"items"`
+}
+
+type hookBodyFieldsOutput struct {
+	Tracked bool            `json:"tracked"`
+	Fields  map[string]bool `json:"fields"`
+}
+
+func hookBodyFieldsOf(r *http.Request) (hookBodyFieldsOutput, error) {
+	fields := hook.BodyFieldsFromContext(r.Context())
+
+	output := hookBodyFieldsOutput{Tracked: fields.Tracked(), Fields: map[string]bool{}}
+	for _, path := range []string{"name", "filter", "filter.sort", "items", "items.price"} {
+		output.Fields[path] = fields.Has(path)
+	}
+
+	return output, nil
+}
+
+func TestBodyFields(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/tracked", kcd.Handler(func(r *http.Request, _ *hookBodyFieldsInput) (hookBodyFieldsOutput, error) {
+		return hookBodyFieldsOf(r)
+	}, http.StatusOK))
+	r.Post("/untracked", kcd.Handler(func(r *http.Request, _ *hookBindStruct) (hookBodyFieldsOutput, error) {
+		return hookBodyFieldsOf(r)
+	}, http.StatusOK))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should record the keys of the objects of the body", func(t *testing.T) {
+		json := e.POST("/tracked").WithJSON(map[string]interface{}{
+			"NAME":   "",
+			"filter": map[string]string{"sort": ""},
+			"items":  []map[string]int{{"price": 1}},
+		}).Expect().Status(http.StatusOK).JSON()
+
+		json.Path("$.tracked").Equal(true)
+		json.Path("$.fields").Equal(map[string]bool{
+			"name":        true,
+			"filter":      true,
+			"filter.sort": true,
+			"items":       true,
+			"items.price": false,
+		})
+	})
+
+	t.Run("it should record the escaped keys by their name", func(t *testing.T) {
+		e.POST("/tracked").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"items": [{"name": "\"}"}], "filter": {"so\u0072t": "{"}}`)).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.fields").Equal(map[string]bool{
+			"name":        false,
+			"filter":      true,
+			"filter.sort": true,
+			"items":       true,
+			"items.price": false,
+		})
+	})
+
+	t.Run("it should not track the body of a route without default", func(t *testing.T) {
+		e.POST("/untracked").WithJSON(map[string]string{"name": ValString}).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.tracked").Equal(false)
+	})
+}
@@ -0,0 +1,364 @@
+package hook
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Codec encodes and decodes the payloads of a media type.
+type Codec interface {
+	// MediaType is the media type of the codec, for instance "application/json".
+	MediaType() string
+
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+// StrictCodec is a codec able to reject the unknown fields of a payload, it is used in strict mode.
+type StrictCodec interface {
+	Codec
+
+	DecodeStrict(r io.Reader, v interface{}) error
+}
+
+// Codecs is a registry of codecs, the order is the order of preference when the client accepts several
+// media types with the same quality.
+type Codecs []Codec
+
+// DefaultCodecs are the codecs used when the route options have none. The other codecs are opt-in, see
+// kcd.WithCodecs, since browsers accept xml with a higher quality than json.
+var DefaultCodecs = Codecs{JSONCodec{}}
+
+// CodecsFromContext returns the codecs of the route options of ctx, or DefaultCodecs if there is none.
+func CodecsFromContext(ctx context.Context) Codecs {
+	if codecs := RouteOptionsFromContext(ctx).Codecs; len(codecs) > 0 {
+		return codecs
+	}
+
+	return DefaultCodecs
+}
+
+// With returns a copy of the registry with the codecs, they replace the codecs of the same media type.
+func (c Codecs) With(codecs ...Codec) Codecs {
+	registry := make(Codecs, 0, len(c)+len(codecs))
+	registry = append(registry, c...)
+
+	for _, codec := range codecs {
+		if i := registry.index(codec.MediaType()); i >= 0 {
+			registry[i] = codec
+		} else {
+			registry = append(registry, codec)
+		}
+	}
+
+	return registry
+}
+
+// Lookup returns the codec of the media type, parameters like the charset are ignored.
+func (c Codecs) Lookup(mediaType string) (Codec, bool) {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+
+	if i := c.index(mediaType); i >= 0 {
+		return c[i], true
+	}
+
+	return nil, false
+}
+
+// Negotiate returns the codec preferred by the Accept header, ok is false if the client accepts none of them.
+// The first codec is returned when the header is empty.
+func (c Codecs) Negotiate(accept string) (codec Codec, ok bool) {
+	if len(c) == 0 {
+		return nil, false
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return c[0], true
+	}
+
+	ranges := parseAccept(accept)
+	best := 0.0
+
+	for _, current := range c {
+		if q := quality(ranges, current.MediaType()); q > best {
+			codec, best = current, q
+		}
+	}
+
+	return codec, codec != nil
+}
+
+func (c Codecs) index(mediaType string) int {
+	for i, codec := range c {
+		if strings.EqualFold(codec.MediaType(), mediaType) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// mediaRange is a media range of an Accept header.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	ranges := make([]mediaRange, 0, strings.Count(accept, ",")+1)
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+
+	return ranges
+}
+
+// quality returns the quality of the most specific media range matching the media type.
+func quality(ranges []mediaRange, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	mainType := strings.SplitN(mediaType, "/", 2)[0]
+
+	for _, r := range ranges {
+		current := -1
+
+		switch {
+		case strings.EqualFold(r.mediaType, mediaType):
+			current = 2
+		case strings.EqualFold(r.mediaType, mainType+"/*"):
+			current = 1
+		case r.mediaType == "*/*":
+			current = 0
+		}
+
+		if current > specificity {
+			q, specificity = r.q, current
+		}
+	}
+
+	return q
+}
+
+// JSONCodec is the codec of application/json using the json encoding of the stdlib.
+type JSONCodec struct{}
+
+// MediaType implements Codec.
+func (JSONCodec) MediaType() string { return "application/json" }
+
+// Encode implements Codec.
+func (JSONCodec) Encode(w io.Writer, v interface{}) error {
+	marshal, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(marshal)
+
+	return err
+}
+
+// Decode implements Codec.
+func (JSONCodec) Decode(r io.Reader, v interface{}) error {
+	return decodeJSON(json.NewDecoder(r), v)
+}
+
+// DecodeStrict implements StrictCodec.
+func (JSONCodec) DecodeStrict(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	return decodeJSON(decoder, v)
+}
+
+// decodeJSON decodes a single json value, like json.Unmarshal the data after the value is an error.
+func decodeJSON(decoder *json.Decoder, v interface{}) error {
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if _, err := decoder.Token(); !goerrors.Is(err, io.EOF) {
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("invalid data after the json value at offset %d", decoder.InputOffset())
+	}
+
+	return nil
+}
+
+// XMLCodec is the codec of application/xml using the xml encoding of the stdlib, it is not part of the
+// DefaultCodecs. Maps are not supported by the xml encoding and a slice is encoded without root element.
+type XMLCodec struct{}
+
+// MediaType implements Codec.
+func (XMLCodec) MediaType() string { return "application/xml" }
+
+// Encode implements Codec.
+func (XMLCodec) Encode(w io.Writer, v interface{}) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Decode implements Codec.
+func (XMLCodec) Decode(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// FormCodec is the codec of application/x-www-form-urlencoded, it is not part of the DefaultCodecs.
+// It encodes and decodes url.Values, map[string][]string and map[string]string. The fields of an input are bound
+// by the form extractor, so the bind hook does not decode form bodies with this codec.
+type FormCodec struct{}
+
+// MediaType implements Codec.
+func (FormCodec) MediaType() string { return "application/x-www-form-urlencoded" }
+
+// Encode implements Codec.
+func (FormCodec) Encode(w io.Writer, v interface{}) error {
+	var values url.Values
+
+	switch t := v.(type) {
+	case url.Values:
+		values = t
+	case map[string][]string:
+		values = t
+	case map[string]string:
+		values = make(url.Values, len(t))
+		for key, value := range t {
+			values.Set(key, value)
+		}
+	default:
+		return fmt.Errorf("unable to encode %T as a form", v)
+	}
+
+	_, err := io.WriteString(w, values.Encode())
+
+	return err
+}
+
+// Decode implements Codec.
+func (FormCodec) Decode(r io.Reader, v interface{}) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+
+	switch t := v.(type) {
+	case *url.Values:
+		*t = values
+	case *map[string][]string:
+		*t = values
+	case *map[string]string:
+		*t = make(map[string]string, len(values))
+		for key := range values {
+			(*t)[key] = values.Get(key)
+		}
+	default:
+		return fmt.Errorf("unable to decode a form into %T", v)
+	}
+
+	return nil
+}
+
+// YAMLCodec is the codec of application/yaml, it is not part of the DefaultCodecs.
+type YAMLCodec struct{}
+
+// MediaType implements Codec.
+func (YAMLCodec) MediaType() string { return "application/yaml" }
+
+// Encode implements Codec.
+func (YAMLCodec) Encode(w io.Writer, v interface{}) error {
+	return yaml.NewEncoder(w).Encode(v)
+}
+
+// Decode implements Codec.
+func (YAMLCodec) Decode(r io.Reader, v interface{}) error {
+	return yaml.NewDecoder(r).Decode(v)
+}
+
+// TextCodec is the codec of text/plain, it is not part of the DefaultCodecs.
+// It encodes strings, []byte, errors, fmt.Stringer and encoding.TextMarshaler and decodes into *string,
+// *[]byte and encoding.TextUnmarshaler.
+type TextCodec struct{}
+
+// MediaType implements Codec.
+func (TextCodec) MediaType() string { return "text/plain" }
+
+// Encode implements Codec.
+func (TextCodec) Encode(w io.Writer, v interface{}) error {
+	var text string
+
+	switch t := v.(type) {
+	case string:
+		text = t
+	case []byte:
+		text = string(t)
+	case encoding.TextMarshaler:
+		marshal, err := t.MarshalText()
+		if err != nil {
+			return err
+		}
+
+		text = string(marshal)
+	case error:
+		text = t.Error()
+	case fmt.Stringer:
+		text = t.String()
+	default:
+		return fmt.Errorf("unable to encode %T as text", v)
+	}
+
+	_, err := io.WriteString(w, text)
+
+	return err
+}
+
+// Decode implements Codec.
+func (TextCodec) Decode(r io.Reader, v interface{}) error {
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	switch t := v.(type) {
+	case *string:
+		*t = string(text)
+	case *[]byte:
+		*t = text
+	case encoding.TextUnmarshaler:
+		return t.UnmarshalText(text)
+	default:
+		return fmt.Errorf("unable to decode text into %T", v)
+	}
+
+	return nil
+}
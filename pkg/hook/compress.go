@@ -0,0 +1,156 @@
+package hook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultCompressionMinSize is the minimum size of a compressed response when Compression.MinSize is zero.
+const DefaultCompressionMinSize = 1024
+
+// DefaultCompressionContentTypes are the compressed media types when Compression.ContentTypes is empty.
+var DefaultCompressionContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/xml",
+	"application/yaml",
+	"text/*",
+}
+
+// compressionEncodings are the supported encodings by order of preference.
+var compressionEncodings = []string{"gzip", "deflate"}
+
+// Compression configures the compression of the responses of the render hook, negotiated from the
+// Accept-Encoding header with gzip or deflate.
+type Compression struct {
+	// MinSize is the minimum size in bytes of a compressed body, DefaultCompressionMinSize is used if zero.
+	MinSize int
+
+	// ContentTypes are the compressed media types, "type/*" matches all the subtypes.
+	// DefaultCompressionContentTypes are used if empty.
+	ContentTypes []string
+}
+
+// compress returns the body compressed with the encoding accepted by the client, if the body must be compressed.
+// It adds Accept-Encoding to the Vary header of the response when the body is compressible.
+func (c Compression) compress(w http.ResponseWriter, r *http.Request, body []byte) ([]byte, string, error) {
+	minSize := c.MinSize
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+
+	contentTypes := c.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = DefaultCompressionContentTypes
+	}
+
+	// the response is already encoded, for instance by a handler writing compressed data.
+	if w.Header().Get("Content-Encoding") != "" || len(body) < minSize {
+		return body, "", nil
+	}
+
+	if !matchMediaTypes(contentTypes, w.Header().Get("Content-Type")) {
+		return body, "", nil
+	}
+
+	addVary(w.Header(), "Accept-Encoding")
+
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+	if encoding == "" {
+		return body, "", nil
+	}
+
+	var (
+		buffer bytes.Buffer
+		writer io.WriteCloser
+	)
+
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buffer)
+	case "deflate":
+		writer = zlib.NewWriter(&buffer)
+	}
+
+	if _, err := writer.Write(body); err != nil {
+		return nil, "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buffer.Bytes(), encoding, nil
+}
+
+// negotiateEncoding returns the supported encoding preferred by the Accept-Encoding header, or an empty string.
+func negotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := map[string]float64{}
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+
+		for _, param := range params[1:] {
+			if value := strings.TrimSpace(param); strings.HasPrefix(value, "q=") {
+				if parsed, err := strconv.ParseFloat(strings.TrimPrefix(value, "q="), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		qualities[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+
+	for _, encoding := range compressionEncodings {
+		q, ok := qualities[encoding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// matchMediaTypes returns true if the media type of the content type matches one of the patterns.
+func matchMediaTypes(patterns []string, contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+
+	for _, pattern := range patterns {
+		if pattern == mediaType || pattern == "*/*" ||
+			(strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addVary adds the header name to the Vary header if it is not already there.
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, token := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), name) {
+				return
+			}
+		}
+	}
+
+	header.Add("Vary", name)
+}
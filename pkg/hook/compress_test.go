@@ -0,0 +1,103 @@
+package hook_test
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/hook"
+)
+
+func hookCompressHandler() (hookRenderStruct, error) {
+	return hookRenderStruct{Name: strings.Repeat("kcd", 1024)}, nil
+}
+
+func hookCompressSmallHandler() (hookRenderStruct, error) {
+	return hookRenderStruct{Name: "kcd"}, nil
+}
+
+func TestCompress(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/", kcd.Handler(hookCompressHandler, 200, kcd.WithCompression(hook.Compression{})))
+	r.Get("/small", kcd.Handler(hookCompressSmallHandler, 200, kcd.WithCompression(hook.Compression{})))
+	r.Get("/uncompressed", kcd.Handler(hookCompressHandler, 200))
+	r.Get("/xml-only", kcd.Handler(hookCompressHandler, 200, kcd.WithCompression(hook.Compression{
+		ContentTypes: []string{"application/xml"},
+	})))
+	r.With(middleware.Compress(5)).
+		Get("/outer", kcd.Handler(hookCompressHandler, 200, kcd.WithCompression(hook.Compression{})))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	get := func(t *testing.T, path, acceptEncoding string) (*http.Response, hookRenderStruct) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+
+		// setting the header disables the transparent decompression of the transport.
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		var body io.Reader = resp.Body
+
+		switch resp.Header.Get("Content-Encoding") {
+		case "gzip":
+			body, err = gzip.NewReader(body)
+			require.NoError(t, err)
+		case "deflate":
+			body, err = zlib.NewReader(body)
+			require.NoError(t, err)
+		}
+
+		var out hookRenderStruct
+		require.NoError(t, json.NewDecoder(body).Decode(&out))
+
+		return resp, out
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		encoding       string
+		vary           bool
+	}{
+		{"it should compress with gzip", "/", "gzip", "gzip", true},
+		{"it should compress with deflate", "/", "deflate", "deflate", true},
+		{"it should prefer the encoding with the highest quality", "/", "gzip;q=0.5, deflate", "deflate", true},
+		{"it should prefer gzip with the wildcard", "/", "*", "gzip", true},
+		{"it should not compress with the identity encoding", "/", "identity", "", true},
+		{"it should not compress a rejected encoding", "/", "gzip;q=0", "", true},
+		{"it should not compress a small body", "/small", "gzip", "", false},
+		{"it should not compress without compression", "/uncompressed", "gzip", "", false},
+		{"it should not compress a content type not allowed", "/xml-only", "gzip", "", false},
+		{"it should not compress twice with an outer compression", "/outer", "gzip", "gzip", true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			resp, out := get(t, test.path, test.acceptEncoding)
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, test.encoding, resp.Header.Get("Content-Encoding"))
+			require.Equal(t, test.vary, strings.Contains(strings.Join(resp.Header.Values("Vary"), ","), "Accept-Encoding"))
+			require.Equal(t, "kcd", out.Name[:3])
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package hook
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/alexisvisco/kcd/internal/kcderr"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// decompressedBody is a decompressed body which closes the decompressor and the original body.
+type decompressedBody struct {
+	io.Reader
+
+	closers []io.Closer
+}
+
+func (b *decompressedBody) Close() error {
+	var err error
+
+	for _, closer := range b.closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// decompress replaces the body of the request by its decompressed content according to the Content-Encoding
+// header, it supports gzip, deflate and br. An unsupported encoding is a KindUnsupportedMediaType error.
+func decompress(r *http.Request) error {
+	header := r.Header.Get("Content-Encoding")
+	if header == "" {
+		return nil
+	}
+
+	encodings := strings.Split(header, ",")
+	body := &decompressedBody{Reader: r.Body, closers: []io.Closer{r.Body}}
+
+	// the encodings are listed in the order they were applied.
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+
+		switch encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			reader, err := gzip.NewReader(body.Reader)
+			if err != nil {
+				return decompressError(err, encoding)
+			}
+
+			body.Reader = reader
+			body.closers = append([]io.Closer{reader}, body.closers...)
+		case "deflate":
+			reader, err := zlib.NewReader(body.Reader)
+			if err != nil {
+				return decompressError(err, encoding)
+			}
+
+			body.Reader = reader
+			body.closers = append([]io.Closer{reader}, body.closers...)
+		case "br":
+			body.Reader = brotli.NewReader(body.Reader)
+		default:
+			return errors.NewWithKind(errors.KindUnsupportedMediaType, "unsupported content encoding %q", encoding).
+				WithField("content-encoding", header)
+		}
+	}
+
+	r.Body = body
+	r.ContentLength = -1
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+
+	return nil
+}
+
+func decompressError(err error, encoding string) error {
+	return errors.Wrap(err, "unable to decompress the "+encoding+" body").
+		WithKind(kcderr.Input).
+		WithField("decoding-strategy", "body")
+}
@@ -0,0 +1,93 @@
+package hook_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexisvisco/kcd"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var (
+		buffer bytes.Buffer
+		writer io.WriteCloser
+	)
+
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buffer)
+	case "deflate":
+		writer = zlib.NewWriter(&buffer)
+	case "br":
+		writer = brotli.NewWriter(&buffer)
+	}
+
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buffer.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/", kcd.Handler(hookBindHandler, 200, kcd.WithMaxBodyBytes(64*1024)))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	for _, encoding := range []string{"gzip", "deflate", "br"} {
+		encoding := encoding
+
+		t.Run("it should decompress a "+encoding+" body", func(t *testing.T) {
+			e.POST("/").
+				WithHeader("Content-Type", "application/json").
+				WithHeader("Content-Encoding", encoding).
+				WithBytes(compress(t, encoding, []byte(`{"name": "kcd"}`))).Expect().
+				Status(http.StatusOK).
+				JSON().Path("$.name").Equal("kcd")
+		})
+	}
+
+	t.Run("it should apply the limit to the decompressed body", func(t *testing.T) {
+		body := compress(t, "gzip", []byte(`{"name": "`+strings.Repeat("a", 1<<20)+`"}`))
+		require.Less(t, len(body), 64*1024)
+
+		e.POST("/").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Content-Encoding", "gzip").
+			WithBytes(body).Expect().
+			Status(http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("it should reject an invalid compressed body", func(t *testing.T) {
+		e.POST("/").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Content-Encoding", "gzip").
+			WithBytes([]byte(`{"name": "kcd"}`)).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Path("$.error_description").Equal("unable to decompress the gzip body")
+	})
+
+	t.Run("it should reject an unsupported encoding", func(t *testing.T) {
+		e.POST("/").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Content-Encoding", "compress").
+			WithBytes([]byte(`{"name": "kcd"}`)).Expect().
+			Status(http.StatusUnsupportedMediaType).
+			JSON().Path("$.error_description").Equal(`unsupported content encoding "compress"`)
+	})
+}
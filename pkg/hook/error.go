@@ -1,8 +1,11 @@
 package hook
 
 import (
-	"encoding/json"
+	"bytes"
+	"encoding/xml"
+	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/alexisvisco/kcd/pkg/errors"
 	validation "github.com/alexisvisco/ozzo-validation/v4"
@@ -26,6 +29,34 @@ type ErrorResponse struct {
 // It check the error and return the corresponding response to the client.
 // logger parameter is optional (you can set it to nil)
 func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
+	statusCode, response := errorResponse(w, r, err, logger)
+
+	// the error is rendered with the first codec when the client accepts none of them.
+	codecs := CodecsFromContext(r.Context())
+	codec, ok := codecs.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		codec = codecs[0]
+	}
+
+	// the response is encoded before the header is written, it falls back to json if the codec fails.
+	var body bytes.Buffer
+	if err := codec.Encode(&body, response); err != nil {
+		codec = JSONCodec{}
+
+		body.Reset()
+		_ = codec.Encode(&body, response)
+	}
+
+	addVary(w.Header(), "Accept")
+	w.Header().Set("Content-type", codec.MediaType())
+	w.WriteHeader(statusCode)
+
+	_, _ = w.Write(body.Bytes())
+}
+
+// errorResponse returns the status code and the response of the error, it logs the error when needed.
+func errorResponse(w http.ResponseWriter, r *http.Request, err error, logger LogHook) (int, ErrorResponse) {
+	statusCode := http.StatusInternalServerError
 	response := ErrorResponse{
 		ErrorDescription: "internal server error",
 		Error:            errors.KindInternal,
@@ -33,8 +64,6 @@ func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
 		Metadata:         map[string]interface{}{},
 	}
 
-	w.Header().Set("Content-type", "application/json")
-
 	reqID := middleware.GetReqID(r.Context())
 	if reqID != "" {
 		response.Metadata["request_id"] = reqID
@@ -42,7 +71,7 @@ func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
 
 	switch e := err.(type) {
 	case validation.Errors:
-		w.WriteHeader(http.StatusBadRequest)
+		statusCode = StatusCode(r.Context(), errors.KindInvalidArgument)
 		response.Error = errors.KindInvalidArgument
 		response.ErrorDescription = "the request has one or multiple invalid fields"
 
@@ -51,9 +80,10 @@ func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
 		}
 	case *errors.Error:
 		if e.Kind == kcderr.Input {
-			w.WriteHeader(http.StatusBadRequest)
+			// the input errors are invalid arguments, their status code can be overridden like the one of the kind.
+			statusCode = StatusCode(r.Context(), errors.KindInvalidArgument)
 			response.Error = errors.KindInvalidArgument
-			response.ErrorDescription = http.StatusText(http.StatusBadRequest)
+			response.ErrorDescription = http.StatusText(statusCode)
 
 			// TODO(alexis) 23/08/2020: maybe handle ctx decoding strategy as a internal server error because it
 			//                          is handled by the input provided by the developer and it is not an user input.
@@ -62,12 +92,27 @@ func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
 			path, _ := e.GetField("path")
 
 			switch decodingStrategy {
-			case "query", "path", "header", "ctx", "default", "form":
-				response.Fields[path.(string)] = e.Message
-			case "json":
+			case "query", "path", "header", "ctx", "default", "form", "file", "cookie", "json", "body":
+				// the errors of the body are about a key only when they have a path, like a missing required key.
+				if p, ok := path.(string); ok && p != "" {
+					response.Fields[p] = e.Message
+				} else {
+					response.ErrorDescription = e.Message
+				}
+			case nil:
+				// the errors of several fields aggregated by the decoder.
 				response.ErrorDescription = e.Message
 			}
 
+			// an error may be about several fields, like the unknown parameters of the strict mode.
+			if fields, ok := e.GetField("fields"); ok {
+				if fields, ok := fields.(map[string]string); ok {
+					for key, message := range fields {
+						response.Fields[key] = message
+					}
+				}
+			}
+
 			if e.Kind.ToStatusCode() >= ErrorHookStatusCodeMinLogged {
 				if logger != nil {
 					logger(w, r, e)
@@ -78,7 +123,6 @@ func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
 		}
 
 		if e.Kind == kcderr.InputCritical {
-			w.WriteHeader(http.StatusInternalServerError)
 			response.Error = e.Kind
 			response.ErrorDescription = e.Message
 
@@ -90,7 +134,6 @@ func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
 		}
 
 		if e.Kind == kcderr.OutputCritical {
-			w.WriteHeader(http.StatusInternalServerError)
 			response.Error = e.Kind
 			response.ErrorDescription = e.Message
 
@@ -101,23 +144,22 @@ func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
 			break
 		}
 
-		w.WriteHeader(e.Kind.ToStatusCode())
+		statusCode = StatusCode(r.Context(), e.Kind)
 
 		response.ErrorDescription = e.Message
 		response.Error = e.Kind
 
-		if e.Kind.ToStatusCode() == 500 {
+		if statusCode == 500 {
 			// ensure there is an error internal if the status code is 500 (for instance when omission of the kind)
 			response.Error = errors.KindInternal
 		}
 
-		if e.Kind.ToStatusCode() >= ErrorHookStatusCodeMinLogged {
+		if statusCode >= ErrorHookStatusCodeMinLogged {
 			if logger != nil {
 				logger(w, r, e)
 			}
 		}
 	default:
-		w.WriteHeader(http.StatusInternalServerError)
 		response.Error = errors.KindInternal
 
 		if logger != nil {
@@ -125,10 +167,51 @@ func Error(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
 		}
 	}
 
-	marshal, err := json.Marshal(response)
-	if err != nil {
-		return
+	return statusCode, response
+}
+
+// String returns the kind and the description of the error, it is used by the text codec.
+func (e ErrorResponse) String() string {
+	return string(e.Error) + ": " + e.ErrorDescription
+}
+
+// MarshalXML encodes the error response, the fields and the metadata are encoded as lists of entries since
+// maps are not supported by the xml encoding.
+func (e ErrorResponse) MarshalXML(encoder *xml.Encoder, _ xml.StartElement) error {
+	type entry struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+
+	response := struct {
+		XMLName          xml.Name    `xml:"error_response"`
+		ErrorDescription string      `xml:"error_description"`
+		Error            errors.Kind `xml:"error"`
+		Fields           []entry     `xml:"fields>field,omitempty"`
+		Metadata         []entry     `xml:"metadata>entry,omitempty"`
+	}{
+		ErrorDescription: e.ErrorDescription,
+		Error:            e.Error,
+	}
+
+	for _, key := range sortedKeys(e.Fields) {
+		response.Fields = append(response.Fields, entry{Key: key, Value: e.Fields[key]})
 	}
 
-	_, _ = w.Write(marshal)
+	for _, key := range sortedKeys(e.Metadata) {
+		response.Metadata = append(response.Metadata, entry{Key: key, Value: fmt.Sprint(e.Metadata[key])})
+	}
+
+	return encoder.Encode(response)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/go-chi/chi/middleware"
 
 	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/hook"
 )
 
 type hookErrorStruct struct {
@@ -36,7 +37,7 @@ func TestError(t *testing.T) {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
-	r.Post("/x", kcd.Handler(hookErrorHandler, 200))
+	r.Post("/x", kcd.Handler(hookErrorHandler, 200, kcd.WithCodecs(hook.XMLCodec{}, hook.FormCodec{})))
 
 	server := httptest.NewServer(r)
 	defer server.Close()
@@ -55,6 +56,29 @@ func TestError(t *testing.T) {
 			JSON().Path("$.error_description").Equal("value is unavailable")
 	})
 
+	t.Run("it should render the error in the accepted format", func(t *testing.T) {
+		e.POST("/x").WithQuery("value", "ab").WithHeader("Accept", "application/xml").Expect().
+			Status(http.StatusBadRequest).
+			ContentType("application/xml").
+			Body().
+			Contains("<error>invalid_argument</error>").
+			Contains(`<fields><field key="value">invalid integer</field></fields>`)
+	})
+
+	t.Run("it should render the error in json when no format is accepted", func(t *testing.T) {
+		e.POST("/x").WithHeader("Accept", "text/html").Expect().
+			Status(http.StatusServiceUnavailable).
+			ContentType("application/json").
+			JSON().Path("$.error_description").Equal("value is unavailable")
+	})
+
+	t.Run("it should render the error in json when the format fails to encode it", func(t *testing.T) {
+		e.POST("/x").WithHeader("Accept", "application/x-www-form-urlencoded").Expect().
+			Status(http.StatusServiceUnavailable).
+			ContentType("application/json").
+			JSON().Path("$.error_description").Equal("value is unavailable")
+	})
+
 	t.Run("it should use normal error", func(t *testing.T) {
 		e.POST("/x").WithQuery("value", "50").Expect().
 			Status(http.StatusInternalServerError)
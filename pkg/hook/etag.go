@@ -0,0 +1,102 @@
+package hook
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// ETagger is implemented by the outputs which know their version, the render hook sends it as the ETag header
+// instead of computing one from the body.
+type ETagger interface {
+	// ETag returns the entity tag of the output, quoted or not, a weak tag starts with W/.
+	ETag() string
+}
+
+// ETag returns a strong entity tag computed from the body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+}
+
+// CheckPreconditions checks the If-Match and If-None-Match headers of a write request against the current
+// entity tag of the resource, quoted or not, empty if the resource does not exist.
+// It returns a KindFailedPrecondition error if a precondition fails, for instance:
+//
+//	func updateArticle(r *http.Request, in *UpdateArticleInput) (*Article, error) {
+//		article := load(in.ID)
+//		if err := hook.CheckPreconditions(r, article.Version); err != nil {
+//			return nil, err
+//		}
+//		...
+//	}
+func CheckPreconditions(r *http.Request, current string) error {
+	if current != "" {
+		current = quoteETag(current)
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && !matchETag(ifMatch, current, false) {
+		return errors.NewWithKind(errors.KindFailedPrecondition, "the resource does not match the If-Match header").
+			WithField("if-match", ifMatch)
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && matchETag(ifNoneMatch, current, true) {
+		return errors.NewWithKind(errors.KindFailedPrecondition, "the resource matches the If-None-Match header").
+			WithField("if-none-match", ifNoneMatch)
+	}
+
+	return nil
+}
+
+// notModified returns true if the response of a GET or HEAD request with the entity tag is not modified.
+func notModified(r *http.Request, etag string) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	ifNoneMatch := r.Header.Get("If-None-Match")
+
+	return ifNoneMatch != "" && matchETag(ifNoneMatch, etag, true)
+}
+
+// matchETag returns true if the entity tag matches one of the tags of the header, with the weak comparison
+// when weak is true and with the strong comparison otherwise. An empty entity tag only matches nothing.
+func matchETag(header, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		switch {
+		case tag == "*":
+			return true
+		case weak && strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/"):
+			return true
+		case !weak && tag == etag && !strings.HasPrefix(tag, "W/"):
+			return true
+		}
+	}
+
+	return false
+}
+
+// quoteETag quotes the entity tag if it is not already.
+func quoteETag(etag string) string {
+	weak := strings.HasPrefix(etag, "W/")
+	etag = strings.TrimPrefix(etag, "W/")
+
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) || len(etag) < 2 {
+		etag = `"` + etag + `"`
+	}
+
+	if weak {
+		return "W/" + etag
+	}
+
+	return etag
+}
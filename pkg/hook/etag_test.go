@@ -0,0 +1,112 @@
+package hook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/hook"
+)
+
+type hookETagArticle struct {
+	Name    string `json:"name"`
+	Version string `json:"-"`
+}
+
+func (a hookETagArticle) ETag() string {
+	return a.Version
+}
+
+func hookETagHandler() (hookRenderStruct, error) {
+	return hookRenderStruct{Name: ValString}, nil
+}
+
+func hookETaggerHandler() (hookETagArticle, error) {
+	return hookETagArticle{Name: ValString, Version: "v2"}, nil
+}
+
+func hookETagUpdateHandler(r *http.Request) (hookETagArticle, error) {
+	if err := hook.CheckPreconditions(r, "v2"); err != nil {
+		return hookETagArticle{}, err
+	}
+
+	return hookETagArticle{Name: ValString, Version: "v3"}, nil
+}
+
+func TestETag(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/", kcd.Handler(hookETagHandler, 200, kcd.WithETag(true)))
+	r.Head("/", kcd.Handler(hookETagHandler, 200, kcd.WithETag(true)))
+	r.Get("/none", kcd.Handler(hookETagHandler, 200))
+	r.Get("/tagger", kcd.Handler(hookETaggerHandler, 200))
+	r.Put("/tagger", kcd.Handler(hookETagUpdateHandler, 200))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should set an etag computed from the body", func(t *testing.T) {
+		etag := e.GET("/").Expect().Status(http.StatusOK).Header("ETag").NotEmpty().Raw()
+
+		e.GET("/").Expect().Status(http.StatusOK).Header("ETag").Equal(etag)
+	})
+
+	t.Run("it should answer not modified with a matching If-None-Match", func(t *testing.T) {
+		etag := e.GET("/").Expect().Header("ETag").Raw()
+
+		e.GET("/").WithHeader("If-None-Match", `"other", W/`+etag).Expect().
+			Status(http.StatusNotModified).
+			Body().Empty()
+
+		e.HEAD("/").WithHeader("If-None-Match", "*").Expect().
+			Status(http.StatusNotModified)
+	})
+
+	t.Run("it should render the body with a different If-None-Match", func(t *testing.T) {
+		e.GET("/").WithHeader("If-None-Match", `"other"`).Expect().
+			Status(http.StatusOK).
+			JSON().Path("$.name").Equal(ValString)
+	})
+
+	t.Run("it should not set an etag by default", func(t *testing.T) {
+		e.GET("/none").Expect().Status(http.StatusOK).Headers().NotContainsKey("Etag")
+	})
+
+	t.Run("it should set the etag of an ETagger output", func(t *testing.T) {
+		e.GET("/tagger").Expect().Status(http.StatusOK).Header("ETag").Equal(`"v2"`)
+
+		e.GET("/tagger").WithHeader("If-None-Match", `"v2"`).Expect().
+			Status(http.StatusNotModified)
+	})
+
+	t.Run("it should update with a matching If-Match", func(t *testing.T) {
+		e.PUT("/tagger").WithHeader("If-Match", `"v2"`).Expect().
+			Status(http.StatusOK).
+			Header("ETag").Equal(`"v3"`)
+	})
+
+	t.Run("it should update without precondition", func(t *testing.T) {
+		e.PUT("/tagger").Expect().Status(http.StatusOK)
+	})
+
+	t.Run("it should fail with a different If-Match", func(t *testing.T) {
+		e.PUT("/tagger").WithHeader("If-Match", `"v1"`).Expect().
+			Status(http.StatusPreconditionFailed).
+			JSON().Path("$.error_description").Equal("the resource does not match the If-Match header")
+	})
+
+	t.Run("it should fail with a weak If-Match", func(t *testing.T) {
+		e.PUT("/tagger").WithHeader("If-Match", `W/"v2"`).Expect().
+			Status(http.StatusPreconditionFailed)
+	})
+
+	t.Run("it should fail with a matching If-None-Match", func(t *testing.T) {
+		e.PUT("/tagger").WithHeader("If-None-Match", "*").Expect().
+			Status(http.StatusPreconditionFailed)
+	})
+}
@@ -29,3 +29,7 @@ type ValidateHook func(ctx context.Context, input interface{}) error
 // LogHook is the logger triggered after the error hook.
 // It can show detailed error about a problem that you can't explain to users.
 type LogHook func(w http.ResponseWriter, r *http.Request, err error)
+
+// TraceHook receives the information about a request which are not errors, like the binding of each field of the
+// input in verbose mode.
+type TraceHook func(r *http.Request, message string, fields map[string]interface{})
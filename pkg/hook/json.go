@@ -0,0 +1,94 @@
+package hook
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/alexisvisco/kcd/internal/kcderr"
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// jsonBodyField is the field of the errors which are not about a specific field of the body.
+const jsonBodyField = "body"
+
+// jsonError translates an error of the json decoding of the input into an error with the field at fault,
+// keyed by its JSON path like "items[2].price", in the "fields" field of the error.
+func jsonError(err error) error {
+	var (
+		typeErr   *json.UnmarshalTypeError
+		pathErr   *jsonPathError
+		syntaxErr *json.SyntaxError
+		field     = jsonBodyField
+		message   string
+		offset    int64
+	)
+
+	switch {
+	case goerrors.As(err, &typeErr):
+		if goerrors.As(err, &pathErr) && pathErr.path != "" {
+			field = pathErr.path
+		}
+
+		value := typeErr.Value
+		if value == "bool" {
+			value = "boolean"
+		}
+
+		offset = typeErr.Offset
+		message = fmt.Sprintf("invalid type: expected %s, got %s (offset %d)", jsonType(typeErr.Type), value, offset)
+	case goerrors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+		message = fmt.Sprintf("invalid json: %s (offset %d)", syntaxErr.Error(), offset)
+	case goerrors.Is(err, io.ErrUnexpectedEOF):
+		message = "invalid json: unexpected end of JSON input"
+	default:
+		message = "invalid json: " + err.Error()
+	}
+
+	return errors.Wrap(err, "unable to read json request").
+		WithKind(kcderr.Input).
+		WithField("decoding-strategy", "json").
+		WithField("offset", offset).
+		WithField("fields", map[string]string{field: message})
+}
+
+// jsonPathError is an error of the json decoding with the path of the value at fault.
+type jsonPathError struct {
+	err  error
+	path string
+}
+
+func (e *jsonPathError) Error() string { return e.err.Error() }
+
+func (e *jsonPathError) Unwrap() error { return e.err }
+
+// jsonType returns the name of the json type of the go type t.
+func jsonType(t reflect.Type) string {
+	switch indirect(t).Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+
+	return t.String()
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
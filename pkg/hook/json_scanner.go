@@ -0,0 +1,396 @@
+package hook
+
+import (
+	"encoding"
+	"encoding/json"
+	goerrors "errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alexisvisco/kcd/internal/types"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonNumberType      = reflect.TypeOf(json.Number(""))
+)
+
+// jsonKind is the kind of a json value.
+type jsonKind int
+
+const (
+	jsonObject jsonKind = iota
+	jsonArray
+	jsonString
+	jsonNumber
+	jsonBool
+	jsonNull
+)
+
+// scanState is what the jsonScanner expects from the next byte.
+type scanState int
+
+const (
+	scanValue scanState = iota
+	scanValueOrEnd
+	scanKeyOrEnd
+	scanKey
+	scanColon
+	scanString
+	scanLiteral
+	scanAfterValue
+	scanDone
+)
+
+// scanFrame is an object or an array containing the value being read.
+type scanFrame struct {
+	array bool
+	index int
+	key   string
+
+	// t is the type of the container, nil if the types of its values are unknown.
+	t reflect.Type
+
+	// record is true if the keys of the object are recorded, the values of the arrays are not.
+	record bool
+}
+
+// jsonScanner reads a json body for a decoder and follows the path of its values while they are read, so the body
+// is decoded as a stream. It finds the first value whose type does not match the input, which is the value at
+// fault of a type error of the decoder, see withPath, and it records the paths of the keys of the objects.
+type jsonScanner struct {
+	r      io.Reader
+	root   reflect.Type
+	offset int64
+	state  scanState
+	stack  []*scanFrame
+
+	// paths are the paths of the keys of the objects which are not in an array, if track is true.
+	track bool
+	paths []string
+
+	// token is the key or the literal being read, which starts at tokenStart.
+	token      []byte
+	tokenStart int64
+	escaped    bool
+
+	mismatch      string
+	mismatchStart int64
+	hasMismatch   bool
+}
+
+// newJSONScanner returns a scanner of the json read from r, which is decoded into a value of the type t.
+// The paths of the keys are recorded if track is true, see BodyFields.
+func newJSONScanner(r io.Reader, t reflect.Type, track bool) *jsonScanner {
+	return &jsonScanner{r: r, root: t, track: track}
+}
+
+// Read implements io.Reader.
+func (s *jsonScanner) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+
+	for _, c := range p[:n] {
+		s.scan(c)
+		s.offset++
+	}
+
+	// a literal at the root ends with the body.
+	if err == io.EOF && s.state == scanLiteral {
+		s.endLiteral()
+	}
+
+	return n, err
+}
+
+// withPath adds the path of the value at fault to a type error of the decoding, like "items[2].price".
+// The field of a type error is not used since its content depends on the version of encoding/json.
+func (s *jsonScanner) withPath(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if !goerrors.As(err, &typeErr) || !s.hasMismatch || typeErr.Offset < s.mismatchStart {
+		return err
+	}
+
+	return &jsonPathError{err: err, path: s.mismatch}
+}
+
+// scan reads the byte c, the scanner stops at the first syntax error which is reported by the decoder.
+func (s *jsonScanner) scan(c byte) {
+	switch s.state {
+	case scanDone:
+		return
+	case scanString, scanKey:
+		s.scanString(c)
+		return
+	case scanLiteral:
+		if strings.IndexByte("+-.0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", c) >= 0 {
+			s.token = append(s.token, c)
+			return
+		}
+
+		// the byte after a literal is part of the structure.
+		s.endLiteral()
+	}
+
+	if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+		return
+	}
+
+	switch s.state {
+	case scanValueOrEnd:
+		if c == ']' {
+			s.endContainer()
+			return
+		}
+
+		s.startValue(c)
+	case scanValue:
+		s.startValue(c)
+	case scanKeyOrEnd:
+		switch c {
+		case '}':
+			s.endContainer()
+		case '"':
+			s.state, s.token = scanKey, s.token[:0]
+		default:
+			s.state = scanDone
+		}
+	case scanColon:
+		s.state = scanValue
+		if c != ':' {
+			s.state = scanDone
+		}
+	case scanAfterValue:
+		top := s.stack[len(s.stack)-1]
+
+		switch {
+		case c == ',' && top.array:
+			top.index++
+			s.state = scanValue
+		case c == ',':
+			s.state = scanKeyOrEnd
+		case c == ']' || c == '}':
+			s.endContainer()
+		default:
+			s.state = scanDone
+		}
+	}
+}
+
+func (s *jsonScanner) scanString(c byte) {
+	key := s.state == scanKey
+
+	switch {
+	case s.escaped:
+		s.escaped = false
+	case c == '\\':
+		s.escaped = true
+	case c == '"' && key:
+		var name string
+		if err := json.Unmarshal(append(append([]byte{'"'}, s.token...), '"'), &name); err != nil {
+			name = string(s.token)
+		}
+
+		top := s.stack[len(s.stack)-1]
+		top.key = name
+		s.state = scanColon
+
+		if top.record {
+			s.paths = append(s.paths, s.path())
+		}
+
+		return
+	case c == '"':
+		s.endValue()
+		return
+	}
+
+	if key {
+		s.token = append(s.token, c)
+	}
+}
+
+func (s *jsonScanner) startValue(c byte) {
+	var kind jsonKind
+
+	switch {
+	case c == '{':
+		kind = jsonObject
+	case c == '[':
+		kind = jsonArray
+	case c == '"':
+		kind = jsonString
+	case c == '-' || (c >= '0' && c <= '9') || c == 't' || c == 'f' || c == 'n':
+		s.state, s.token, s.tokenStart = scanLiteral, append(s.token[:0], c), s.offset
+		return
+	default:
+		s.state = scanDone
+		return
+	}
+
+	t := s.expected()
+	s.check(t, kind, "", s.offset)
+
+	record := s.track
+	if len(s.stack) > 0 {
+		top := s.stack[len(s.stack)-1]
+		record = top.record && !top.array
+	}
+
+	switch kind {
+	case jsonObject:
+		s.stack = append(s.stack, &scanFrame{t: containerType(t), record: record})
+		s.state = scanKeyOrEnd
+	case jsonArray:
+		s.stack = append(s.stack, &scanFrame{array: true, t: containerType(t), record: record})
+		s.state = scanValueOrEnd
+	default:
+		s.state = scanString
+	}
+}
+
+func (s *jsonScanner) endLiteral() {
+	kind := jsonNumber
+
+	switch s.token[0] {
+	case 't', 'f':
+		kind = jsonBool
+	case 'n':
+		kind = jsonNull
+	}
+
+	s.check(s.expected(), kind, string(s.token), s.tokenStart)
+	s.endValue()
+}
+
+func (s *jsonScanner) endContainer() {
+	s.stack = s.stack[:len(s.stack)-1]
+	s.endValue()
+}
+
+func (s *jsonScanner) endValue() {
+	s.state = scanAfterValue
+	if len(s.stack) == 0 {
+		s.state = scanDone
+	}
+}
+
+// expected returns the type of the value being read, nil if it is unknown.
+func (s *jsonScanner) expected() reflect.Type {
+	if len(s.stack) == 0 {
+		return s.root
+	}
+
+	top := s.stack[len(s.stack)-1]
+
+	switch {
+	case top.t == nil:
+		return nil
+	case top.t.Kind() == reflect.Struct:
+		field, ok := types.JSONField(top.t, top.key)
+		if !ok || strings.Contains(field.Tag.Get("json"), ",string") {
+			return nil
+		}
+
+		return field.Type
+	default:
+		return top.t.Elem()
+	}
+}
+
+// check records the path of the value starting at start if its kind does not match the type t, and if it is the
+// first one.
+func (s *jsonScanner) check(t reflect.Type, kind jsonKind, literal string, start int64) {
+	if s.hasMismatch || jsonCompatible(t, kind, literal) {
+		return
+	}
+
+	s.hasMismatch, s.mismatch, s.mismatchStart = true, s.path(), start
+}
+
+// path returns the path of the value being read, keys are separated by dots and indexes are between brackets.
+func (s *jsonScanner) path() string {
+	var b strings.Builder
+
+	for _, f := range s.stack {
+		if f.array {
+			b.WriteString("[" + strconv.Itoa(f.index) + "]")
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+
+		b.WriteString(f.key)
+	}
+
+	return b.String()
+}
+
+// containerType returns the type of an object or an array decoded into t, nil if the types of its values are
+// unknown.
+func containerType(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+
+	t = indirect(t)
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return t
+	}
+
+	return nil
+}
+
+// jsonCompatible returns true if a json value of the kind can be decoded into the type t like encoding/json does,
+// literal is the text of a number.
+func jsonCompatible(t reflect.Type, kind jsonKind, literal string) bool {
+	if t == nil || kind == jsonNull {
+		return true
+	}
+
+	t = indirect(t)
+
+	switch {
+	case reflect.PtrTo(t).Implements(jsonUnmarshalerType):
+		return true
+	case kind == jsonString && reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return true
+	case t == jsonNumberType:
+		return kind == jsonNumber || kind == jsonString
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	case reflect.Struct, reflect.Map:
+		return kind == jsonObject
+	case reflect.Slice:
+		return kind == jsonArray || (kind == jsonString && t.Elem().Kind() == reflect.Uint8)
+	case reflect.Array:
+		return kind == jsonArray
+	case reflect.String:
+		return kind == jsonString
+	case reflect.Bool:
+		return kind == jsonBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err := strconv.ParseInt(literal, 10, t.Bits())
+		return kind == jsonNumber && err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		_, err := strconv.ParseUint(literal, 10, t.Bits())
+		return kind == jsonNumber && err == nil
+	case reflect.Float32, reflect.Float64:
+		_, err := strconv.ParseFloat(literal, t.Bits())
+		return kind == jsonNumber && err == nil
+	}
+
+	return true
+}
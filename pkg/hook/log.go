@@ -35,3 +35,18 @@ func Log(_ http.ResponseWriter, r *http.Request, err error) {
 		"method":  r.Method,
 	}).Error()
 }
+
+// Trace will log the message about the request at the info level.
+func Trace(r *http.Request, message string, fields map[string]interface{}) {
+	logger := logrus.WithFields(fields)
+
+	reqID := middleware.GetReqID(r.Context())
+	if reqID != "" {
+		logger = logger.WithField("request-id", reqID)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"request": r.URL.Path,
+		"method":  r.Method,
+	}).Info(message)
+}
@@ -0,0 +1,88 @@
+package hook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+// ProblemMediaType is the media type of the problem details, see RFC 9457.
+const ProblemMediaType = "application/problem+json"
+
+// Problem is the response of the ProblemError hook, the problem details of RFC 9457.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// InvalidParams are the invalid fields of the request, sorted by name.
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+
+	// RequestID is the id of the request set by the chi middleware.RequestID.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// InvalidParam is a field violation of a Problem.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ProblemTypeResolver returns the problem type URI of an error kind.
+type ProblemTypeResolver func(kind errors.Kind) string
+
+// ProblemTypeBlank resolves every kind to "about:blank", the problem is then described by its status code.
+func ProblemTypeBlank(errors.Kind) string {
+	return "about:blank"
+}
+
+// ProblemTypePrefix returns a resolver which appends the kind to the base URI, for instance
+// "https://api.example.com/problems/not_found".
+func ProblemTypePrefix(base string) ProblemTypeResolver {
+	base = strings.TrimSuffix(base, "/") + "/"
+
+	return func(kind errors.Kind) string {
+		return base + string(kind)
+	}
+}
+
+// ProblemError returns an error hook rendering the errors as application/problem+json, the kinds of the errors
+// are converted to problem types with resolve, ProblemTypeBlank if nil.
+// It handles the errors like Error, the description of the error is the detail of the problem and its fields
+// are the invalid params, for instance:
+//
+//	kcd.New(kcd.WithErrorHook(hook.ProblemError(hook.ProblemTypePrefix("https://api.example.com/problems"))))
+func ProblemError(resolve ProblemTypeResolver) ErrorHook {
+	if resolve == nil {
+		resolve = ProblemTypeBlank
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, err error, logger LogHook) {
+		statusCode, response := errorResponse(w, r, err, logger)
+
+		problem := Problem{
+			Type:     resolve(response.Error),
+			Title:    http.StatusText(statusCode),
+			Status:   statusCode,
+			Detail:   response.ErrorDescription,
+			Instance: r.URL.Path,
+		}
+
+		for _, name := range sortedKeys(response.Fields) {
+			problem.InvalidParams = append(problem.InvalidParams, InvalidParam{Name: name, Reason: response.Fields[name]})
+		}
+
+		if reqID, ok := response.Metadata["request_id"].(string); ok {
+			problem.RequestID = reqID
+		}
+
+		w.Header().Set("Content-type", ProblemMediaType)
+		w.WriteHeader(statusCode)
+
+		_ = json.NewEncoder(w).Encode(problem)
+	}
+}
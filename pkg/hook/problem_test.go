@@ -0,0 +1,70 @@
+package hook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/hook"
+)
+
+var problemJSON = httpexpect.ContentOpts{MediaType: hook.ProblemMediaType}
+
+func TestProblemError(t *testing.T) {
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequestID)
+	r.Post("/blank", kcd.Handler(hookErrorHandler, 200, kcd.WithErrorHook(hook.ProblemError(nil))))
+	r.Post("/typed", kcd.Handler(hookErrorHandler, 200, kcd.WithErrorHook(
+		hook.ProblemError(hook.ProblemTypePrefix("https://api.example.com/problems/")),
+	)))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	t.Run("it should render the problem of an error", func(t *testing.T) {
+		problem := e.POST("/blank").Expect().
+			Status(http.StatusServiceUnavailable).
+			JSON(problemJSON).Object()
+
+		problem.ValueEqual("type", "about:blank")
+		problem.ValueEqual("title", "Service Unavailable")
+		problem.ValueEqual("status", http.StatusServiceUnavailable)
+		problem.ValueEqual("detail", "value is unavailable")
+		problem.ValueEqual("instance", "/blank")
+		problem.Value("request_id").String().NotEmpty()
+		problem.NotContainsKey("invalid-params")
+	})
+
+	t.Run("it should render the invalid params", func(t *testing.T) {
+		problem := e.POST("/blank").WithQuery("value", "ab").Expect().
+			Status(http.StatusBadRequest).
+			JSON(problemJSON).Object()
+
+		problem.ValueEqual("title", "Bad Request")
+		problem.ValueEqual("invalid-params", []map[string]string{{"name": "value", "reason": "invalid integer"}})
+	})
+
+	t.Run("it should resolve the type from the kind", func(t *testing.T) {
+		e.POST("/typed").Expect().
+			Status(http.StatusServiceUnavailable).
+			JSON(problemJSON).Path("$.type").Equal("https://api.example.com/problems/unavailable")
+
+		e.POST("/typed").WithQuery("value", "ab").Expect().
+			Status(http.StatusBadRequest).
+			JSON(problemJSON).Path("$.type").Equal("https://api.example.com/problems/invalid_argument")
+	})
+
+	t.Run("it should hide the internal errors", func(t *testing.T) {
+		e.POST("/typed").WithQuery("value", "50").Expect().
+			Status(http.StatusInternalServerError).
+			JSON(problemJSON).Path("$.type").Equal("https://api.example.com/problems/internal")
+	})
+}
@@ -1,7 +1,7 @@
 package hook
 
 import (
-	"encoding/json"
+	"bytes"
 	"net/http"
 
 	"github.com/alexisvisco/kcd/pkg/errors"
@@ -10,21 +10,79 @@ import (
 )
 
 // Render is the default render hook.
-// It marshals the output into JSON, or returns an empty body if the payload is nil.
-func Render(w http.ResponseWriter, _ *http.Request, response interface{}, statusCode int) error {
-	if response != nil {
-		marshal, err := json.Marshal(response)
+// It encodes the output with the codec negotiated from the Accept header, or returns an empty body if the
+// payload is nil. The codecs are the ones of the route, see CodecsFromContext.
+// It returns a KindNotAcceptable error if the client accepts none of the codecs.
+//
+// The body is compressed when the route has a Compression, unless the response already has a Content-Encoding.
+// An outer middleware like chi middleware.Compress only sets its Content-Encoding when the header is written,
+// after the render hook, and it skips the compression of a response which already has one, so the body is
+// never compressed twice.
+//
+// The ETag header is set from the output when it implements ETagger, or from the rendered body when the route
+// enables ETag. A GET or HEAD request with a matching If-None-Match header gets a 304 Not Modified response.
+func Render(w http.ResponseWriter, r *http.Request, response interface{}, statusCode int) error {
+	if response == nil {
+		w.WriteHeader(statusCode)
+		return nil
+	}
+
+	addVary(w.Header(), "Accept")
+
+	codec, ok := CodecsFromContext(r.Context()).Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		return errors.NewWithKind(errors.KindNotAcceptable, "none of the accepted media types can be rendered").
+			WithField("accept", r.Header.Get("Accept"))
+	}
+
+	var body bytes.Buffer
+	if err := codec.Encode(&body, response); err != nil {
+		return errors.Wrap(err, "unable to render response in "+codec.MediaType()+" format").
+			WithKind(kcderr.OutputCritical)
+	}
+
+	w.Header().Set("Content-type", codec.MediaType())
+
+	payload := body.Bytes()
+
+	options := RouteOptionsFromContext(r.Context())
+
+	if compression := options.Compression; compression != nil {
+		compressed, encoding, err := compression.compress(w, r, payload)
 		if err != nil {
-			return errors.Wrap(err, "unable to render response in json format").WithKind(kcderr.OutputCritical)
+			return errors.Wrap(err, "unable to compress response").WithKind(kcderr.OutputCritical)
 		}
 
-		w.Header().Set("Content-type", "application/json")
-		w.WriteHeader(statusCode)
-		if _, err := w.Write(marshal); err != nil {
-			return errors.Wrap(err, "unable to write response").WithKind(kcderr.OutputCritical)
+		if encoding != "" {
+			w.Header().Set("Content-Encoding", encoding)
+			w.Header().Del("Content-Length")
 		}
-	} else {
-		w.WriteHeader(statusCode)
+
+		payload = compressed
+	}
+
+	if statusCode >= 200 && statusCode < 300 {
+		etag := ""
+		if tagger, ok := response.(ETagger); ok {
+			etag = quoteETag(tagger.ETag())
+		} else if options.ETag {
+			etag = ETag(payload)
+		}
+
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+
+			if notModified(r, etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return nil
+			}
+		}
+	}
+
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(payload); err != nil {
+		return errors.Wrap(err, "unable to write response").WithKind(kcderr.OutputCritical)
 	}
 
 	return nil
@@ -9,10 +9,11 @@ import (
 	"github.com/go-chi/chi"
 
 	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/hook"
 )
 
 type hookRenderStruct struct {
-	Name string `json:"name"`
+	Name string `json:"name" xml:"name" yaml:"name"`
 }
 
 func hookRenderEmptyResponseHandler() error {
@@ -54,4 +55,66 @@ func TestRender(t *testing.T) {
 		e.POST("/empty").Expect().
 			Status(http.StatusOK).Body().Equal("")
 	})
+
+	t.Run("it should render json for a browser", func(t *testing.T) {
+		e.POST("/").WithJSON(hookBindStruct{Name: ValString}).
+			WithHeader("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8").Expect().
+			Status(http.StatusOK).
+			ContentType("application/json").
+			JSON().Path("$.name").Equal(ValString)
+	})
+}
+
+func TestRenderNegotiation(t *testing.T) {
+	engine := kcd.New(kcd.WithCodecs(hook.XMLCodec{}, hook.YAMLCodec{}))
+
+	r := chi.NewRouter()
+	r.Get("/", engine.Handler(func() (hookRenderStruct, error) {
+		return hookRenderStruct{Name: "kcd"}, nil
+	}, 200))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	e := httpexpect.New(t, server.URL)
+
+	const (
+		jsonBody = `{"name":"kcd"}`
+		xmlBody  = `<hookRenderStruct><name>kcd</name></hookRenderStruct>`
+	)
+
+	tests := []struct {
+		name        string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"it should render json without accept header", "", 200, "application/json", jsonBody},
+		{"it should render json for any media type", "*/*", 200, "application/json", jsonBody},
+		{"it should render xml", "application/xml", 200, "application/xml", xmlBody},
+		{"it should render a user codec", "application/yaml", 200, "application/yaml", "name: kcd\n"},
+		{"it should use the quality values", "application/json;q=0.5, application/xml;q=0.8", 200,
+			"application/xml", xmlBody},
+		{"it should prefer the most specific range", "application/*;q=0.1, application/xml;q=0, */*;q=0.5", 200,
+			"application/json", jsonBody},
+		{"it should answer not acceptable", "text/html", 406, "application/json", ""},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			response := e.GET("/").WithHeader("Accept", test.accept).Expect().Status(test.status)
+
+			response.Header("Content-Type").Equal(test.contentType)
+			response.Header("Vary").Equal("Accept")
+
+			if test.body != "" {
+				response.Body().Equal(test.body)
+			} else {
+				response.JSON().Path("$.error").Equal("not_acceptable")
+			}
+		})
+	}
 }
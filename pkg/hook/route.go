@@ -0,0 +1,54 @@
+package hook
+
+import (
+	"context"
+
+	"github.com/alexisvisco/kcd/pkg/errors"
+)
+
+type routeOptionsKey struct{}
+
+// RouteOptions are the settings of a route that kcd passes down to the hooks through the request context.
+// The default hooks use them, custom hooks are free to ignore them.
+type RouteOptions struct {
+	// Codecs are the codecs negotiated with the client, see CodecsFromContext.
+	Codecs Codecs
+
+	// Strict tells the bind hook to reject the unknown fields of the body, see StrictCodec.
+	Strict bool
+
+	// NoBody tells the bind hook the route expects no body, the body is ignored.
+	NoBody bool
+
+	// Compression enables the compression of the responses of the render hook when not nil.
+	Compression *Compression
+
+	// ETag tells the render hook to send an ETag computed from the body, see ETagger.
+	ETag bool
+
+	// MaxBodyBytes overrides the maximum number of bytes read from the body when greater than zero.
+	MaxBodyBytes int64
+
+	// KindStatus overrides the http status code of the error kinds.
+	KindStatus map[errors.Kind]int
+}
+
+// ContextWithRouteOptions returns a copy of ctx holding the route options.
+func ContextWithRouteOptions(ctx context.Context, options RouteOptions) context.Context {
+	return context.WithValue(ctx, routeOptionsKey{}, options)
+}
+
+// RouteOptionsFromContext returns the route options of ctx, or zero options if there is none.
+func RouteOptionsFromContext(ctx context.Context) RouteOptions {
+	options, _ := ctx.Value(routeOptionsKey{}).(RouteOptions)
+	return options
+}
+
+// StatusCode returns the http status code of the kind, using the overrides of the route options of ctx if any.
+func StatusCode(ctx context.Context, kind errors.Kind) int {
+	if status, ok := RouteOptionsFromContext(ctx).KindStatus[kind]; ok {
+		return status
+	}
+
+	return kind.ToStatusCode()
+}
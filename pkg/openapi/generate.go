@@ -0,0 +1,287 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/hook"
+)
+
+// parameterLocations are the extractors tags which are OpenAPI parameters, in the order they are documented.
+var parameterLocations = []string{"path", "query", "header", "cookie"}
+
+// Option modifies a generated document.
+type Option func(d *Document)
+
+// WithVersion sets the version of the OpenAPI specification, Version30 or Version31.
+func WithVersion(version string) Option {
+	return func(d *Document) {
+		d.OpenAPI = version
+	}
+}
+
+// WithServers sets the servers of the API.
+func WithServers(servers ...Server) Option {
+	return func(d *Document) {
+		d.Servers = servers
+	}
+}
+
+// Generate returns an OpenAPI document describing the routes, see kcd.Router to record routes.
+//
+// Parameters are documented from the path, query, header and cookie tags of the inputs, the other fields of the
+// inputs are documented as the JSON body, or as a form body when the inputs have form or file tags.
+// The doc and example tags set the description and the example of a parameter or a property.
+// Errors are documented with the hook.ErrorResponse schema.
+func Generate(info Info, routes []kcd.Route, options ...Option) *Document {
+	d := &Document{
+		OpenAPI: Version30,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+	}
+
+	s := newSchemas()
+	errorSchema := s.of(reflect.TypeOf(hook.ErrorResponse{}))
+	operationIDs := map[string]bool{}
+
+	for _, route := range routes {
+		path := pathOf(route.Pattern)
+
+		item, ok := d.Paths[path]
+		if !ok {
+			item = PathItem{}
+			d.Paths[path] = item
+		}
+
+		operation := newOperation(s, route.Endpoint, errorSchema)
+		operation.OperationID = uniqueOperationID(operationIDs, route.Endpoint.Name, route.Method)
+
+		item[strings.ToLower(route.Method)] = operation
+	}
+
+	d.Components.Schemas = s.components
+
+	for _, option := range options {
+		option(d)
+	}
+
+	return d
+}
+
+func newOperation(s *schemas, endpoint *kcd.Endpoint, errorSchema *Schema) *Operation {
+	operation := &Operation{
+		Responses: map[string]*Response{
+			"default": {
+				Description: "Error",
+				Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+			},
+		},
+	}
+
+	success := &Response{Description: http.StatusText(endpoint.StatusCode)}
+	if endpoint.Output != nil {
+		success.Content = map[string]MediaType{"application/json": {Schema: s.of(endpoint.Output)}}
+	}
+
+	operation.Responses[strconv.Itoa(endpoint.StatusCode)] = success
+
+	if endpoint.Input == nil {
+		return operation
+	}
+
+	bound := make(map[string]bool, len(endpoint.Fields))
+	form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	hasFile := false
+
+	for _, field := range endpoint.Fields {
+		// the required keys of the body have no sources, they are documented by the schema of the body.
+		bound[field.Name] = len(field.Sources) > 0
+
+		if name, ok := field.Sources["form"]; ok {
+			form.Properties[name] = formProperty(field)
+			form.Required = appendRequired(form.Required, field, name)
+		}
+
+		if name, ok := field.Sources["file"]; ok {
+			form.Properties[name] = fileProperty(field)
+			form.Required = appendRequired(form.Required, field, name)
+			hasFile = true
+		}
+
+		for _, location := range parameterLocations {
+			name, ok := field.Sources[location]
+			if !ok {
+				continue
+			}
+
+			// options of the tag, like a signed cookie, are not part of the name.
+			name = strings.Split(name, ",")[0]
+
+			operation.Parameters = append(operation.Parameters, newParameter(field, location, name))
+		}
+	}
+
+	if len(form.Properties) > 0 {
+		operation.RequestBody = &RequestBody{
+			Content: map[string]MediaType{"multipart/form-data": {Schema: form}},
+		}
+
+		if !hasFile {
+			operation.RequestBody.Content["application/x-www-form-urlencoded"] = MediaType{Schema: form}
+		}
+
+		return operation
+	}
+
+	body := s.object(endpoint.Input, func(path string) bool { return bound[path] }, "")
+	if len(body.Properties) > 0 {
+		operation.RequestBody = &RequestBody{
+			Content: map[string]MediaType{"application/json": {Schema: body}},
+		}
+	}
+
+	return operation
+}
+
+func newParameter(field kcd.Field, location, name string) Parameter {
+	schema := parameter(field.Type, field.Multiple)
+
+	if def, ok := literalDefault(field.Default); ok {
+		schema.Default = value(schema, def, field.Exploder)
+	}
+
+	parameter := Parameter{
+		Name:        name,
+		In:          location,
+		Description: field.Tag.Get("doc"),
+		Required:    location == "path" || field.Required,
+		Schema:      schema,
+	}
+
+	if raw, ok := field.Tag.Lookup("example"); ok {
+		parameter.Example = value(schema, raw, field.Exploder)
+	}
+
+	if field.Multiple {
+		parameter.Style, parameter.Explode = style(location, field.Exploder)
+	}
+
+	return parameter
+}
+
+// formProperty returns the schema of a field of a multipart form.
+func formProperty(field kcd.Field) *Schema {
+	schema := parameter(field.Type, field.Multiple)
+	schema.Description = field.Tag.Get("doc")
+
+	if def, ok := literalDefault(field.Default); ok {
+		schema.Default = value(schema, def, field.Exploder)
+	}
+
+	return schema
+}
+
+// appendRequired appends the name of the property of the field to the required properties if it is required.
+func appendRequired(required []string, field kcd.Field, name string) []string {
+	if !field.Required {
+		return required
+	}
+
+	return append(required, name)
+}
+
+// fileProperty returns the schema of a file of a multipart form.
+func fileProperty(field kcd.Field) *Schema {
+	schema := &Schema{Type: "string", Format: "binary", Description: field.Tag.Get("doc")}
+
+	if field.Multiple {
+		return &Schema{Type: "array", Items: schema, Description: schema.Description}
+	}
+
+	return schema
+}
+
+// literalDefault returns the literal value of a default tag, the values computed by a provider like "@now" are
+// not documented.
+func literalDefault(def string) (string, bool) {
+	if def == "" || (strings.HasPrefix(def, "@") && !strings.HasPrefix(def, "@@")) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(def, "@"), true
+}
+
+// style returns the serialization style of a parameter with multiple values.
+func style(location, exploder string) (string, *bool) {
+	explode := exploder == ""
+
+	switch {
+	case location != "query":
+		return "simple", &explode
+	case exploder == " ":
+		return "spaceDelimited", &explode
+	case exploder == "|":
+		return "pipeDelimited", &explode
+	}
+
+	return "form", &explode
+}
+
+// pathOf converts a chi pattern into an OpenAPI path, regular expressions of the parameters are removed.
+func pathOf(pattern string) string {
+	var (
+		b     strings.Builder
+		depth int
+		skip  bool
+	)
+
+	for _, c := range pattern {
+		switch {
+		case c == '{':
+			depth++
+			if depth > 1 {
+				continue
+			}
+		case c == '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+
+			skip = false
+		case depth == 1 && c == ':':
+			skip = true
+		}
+
+		if !skip {
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String()
+}
+
+// uniqueOperationID returns an operation id from the name of the handler function.
+func uniqueOperationID(ids map[string]bool, name, method string) string {
+	id := strings.TrimSuffix(name[strings.LastIndex(name, ".")+1:], "-fm")
+	if id == "" || ids[id] {
+		prefix := strings.ToLower(method)
+		if id != "" {
+			id = strings.ToUpper(id[:1]) + id[1:]
+		}
+
+		id = prefix + id
+	}
+
+	base := id
+	for i := 2; ids[id]; i++ {
+		id = base + strconv.Itoa(i)
+	}
+
+	ids[id] = true
+
+	return id
+}
@@ -0,0 +1,188 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexisvisco/kcd"
+	"github.com/alexisvisco/kcd/pkg/openapi"
+)
+
+type address struct {
+	City string `json:"city" doc:"city of the user" example:"Paris"`
+}
+
+type createUserInput struct {
+	Organization string        `path:"organization"`
+	ID           int           `path:"id"`
+	Emails       []string      `query:"emails" exploder:"," doc:"emails of the user"`
+	Roles        []string      `query:"roles,required"`
+	Limit        int           `query:"limit" default:"10" example:"20"`
+	Timeout      time.Duration `header:"X-Timeout"`
+	UserID       string        `ctx:"user_id"`
+
+	Name    string            `json:"name" doc:"name of the user" required:"true"`
+	Age     *int              `json:"age,omitempty"`
+	Address address           `json:"address"`
+	Labels  map[string]string `json:"labels"`
+	Ignored string            `json:"-"`
+}
+
+type user struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Friends   []*user   `json:"friends"`
+}
+
+func createUser(_ *createUserInput) (*user, error) {
+	return &user{}, nil
+}
+
+func deleteUser() error {
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	r := kcd.NewRouter(kcd.New())
+	r.Post("/organizations/{organization}/users/{id:[0-9]+}", createUser, http.StatusCreated)
+	r.Delete("/users/{id}", deleteUser, http.StatusNoContent)
+
+	doc := openapi.Generate(openapi.Info{Title: "users", Version: "1.0.0"}, r.Routes(),
+		openapi.WithVersion(openapi.Version31),
+		openapi.WithServers(openapi.Server{URL: "https://api.example.com"}))
+
+	assert.Equal(t, openapi.Version31, doc.OpenAPI)
+	assert.Equal(t, "https://api.example.com", doc.Servers[0].URL)
+	require.Contains(t, doc.Paths, "/organizations/{organization}/users/{id}")
+	require.Contains(t, doc.Paths, "/users/{id}")
+
+	create := doc.Paths["/organizations/{organization}/users/{id}"]["post"]
+	require.NotNil(t, create)
+
+	t.Run("it should document the operation", func(t *testing.T) {
+		assert.Equal(t, "createUser", create.OperationID)
+		assert.Equal(t, "deleteUser", doc.Paths["/users/{id}"]["delete"].OperationID)
+	})
+
+	t.Run("it should document the parameters", func(t *testing.T) {
+		parameters := map[string]openapi.Parameter{}
+		for _, p := range create.Parameters {
+			parameters[p.In+":"+p.Name] = p
+		}
+
+		require.Len(t, parameters, 6)
+
+		id := parameters["path:id"]
+		assert.True(t, id.Required)
+		assert.Equal(t, "integer", id.Schema.Type)
+
+		emails := parameters["query:emails"]
+		assert.Equal(t, "array", emails.Schema.Type)
+		assert.Equal(t, "string", emails.Schema.Items.Type)
+		assert.Equal(t, "form", emails.Style)
+		assert.False(t, *emails.Explode)
+		assert.Equal(t, "emails of the user", emails.Description)
+
+		assert.True(t, *parameters["query:roles"].Explode)
+		assert.True(t, parameters["query:roles"].Required)
+
+		limit := parameters["query:limit"]
+		assert.False(t, limit.Required)
+		assert.Equal(t, int64(10), limit.Schema.Default)
+		assert.Equal(t, int64(20), limit.Example)
+
+		timeout := parameters["header:X-Timeout"]
+		assert.Equal(t, "string", timeout.Schema.Type)
+		assert.Equal(t, "duration", timeout.Schema.Format)
+	})
+
+	t.Run("it should document the body", func(t *testing.T) {
+		require.NotNil(t, create.RequestBody)
+
+		body := create.RequestBody.Content["application/json"].Schema
+		assert.Len(t, body.Properties, 4)
+		assert.Equal(t, "string", body.Properties["name"].Type)
+		assert.Equal(t, "name of the user", body.Properties["name"].Description)
+		assert.Equal(t, "integer", body.Properties["age"].Type)
+		assert.Equal(t, "#/components/schemas/address", body.Properties["address"].Ref)
+		assert.Equal(t, "string", body.Properties["labels"].AdditionalProperties.Type)
+		assert.Equal(t, []string{"name"}, body.Required)
+
+		city := doc.Components.Schemas["address"].Properties["city"]
+		assert.Equal(t, "city of the user", city.Description)
+		assert.Equal(t, "Paris", city.Example)
+
+		assert.Nil(t, doc.Paths["/users/{id}"]["delete"].RequestBody)
+	})
+
+	t.Run("it should document the responses", func(t *testing.T) {
+		created := create.Responses["201"]
+		require.NotNil(t, created)
+		assert.Equal(t, "#/components/schemas/user", created.Content["application/json"].Schema.Ref)
+
+		u := doc.Components.Schemas["user"]
+		assert.Equal(t, "date-time", u.Properties["created_at"].Format)
+		assert.Equal(t, "#/components/schemas/user", u.Properties["friends"].Items.Ref)
+
+		assert.Nil(t, doc.Paths["/users/{id}"]["delete"].Responses["204"].Content)
+
+		errorResponse := create.Responses["default"].Content["application/json"].Schema
+		assert.Equal(t, "#/components/schemas/ErrorResponse", errorResponse.Ref)
+		assert.Contains(t, doc.Components.Schemas["ErrorResponse"].Properties, "error_description")
+	})
+
+	t.Run("it should serve the document and the pages", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.Handle("/openapi.json", doc.Handler())
+		mux.Handle("/swagger", openapi.SwaggerUI("users", "/openapi.json"))
+		mux.Handle("/redoc", openapi.Redoc("users", "/openapi.json"))
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		e := httpexpect.New(t, server.URL)
+
+		raw := e.GET("/openapi.json").Expect().Status(http.StatusOK).Body().Raw()
+
+		var served map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(raw), &served))
+		assert.Equal(t, openapi.Version31, served["openapi"])
+
+		e.GET("/swagger").Expect().Status(http.StatusOK).Body().Contains(`"/openapi.json"`)
+		e.GET("/redoc").Expect().Status(http.StatusOK).Body().Contains(`spec-url="/openapi.json"`)
+	})
+
+	t.Run("it should load the assets of the pages with their integrity", func(t *testing.T) {
+		defer func(assets openapi.Assets) { openapi.SwaggerUIAssets = assets }(openapi.SwaggerUIAssets)
+
+		openapi.SwaggerUIAssets = openapi.Assets{
+			Script:          "/assets/swagger-ui-bundle.js",
+			ScriptIntegrity: "sha384-script",
+			Stylesheet:      "/assets/swagger-ui.css",
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/swagger", openapi.SwaggerUI("users", "/openapi.json"))
+		mux.Handle("/redoc", openapi.Redoc("users", "/openapi.json"))
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		e := httpexpect.New(t, server.URL)
+
+		swagger := e.GET("/swagger").Expect().Status(http.StatusOK).Body()
+		swagger.Contains(`<script src="/assets/swagger-ui-bundle.js" integrity="sha384-script" crossorigin="anonymous">`)
+		swagger.Contains(`<link rel="stylesheet" href="/assets/swagger-ui.css" crossorigin="anonymous">`)
+
+		e.GET("/redoc").Expect().Status(http.StatusOK).Body().
+			Contains(`<script src="` + openapi.RedocAssets.Script + `" crossorigin="anonymous">`)
+	})
+}
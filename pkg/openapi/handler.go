@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+)
+
+//go:embed ui/*.html
+var ui embed.FS
+
+var (
+	swaggerTemplate = template.Must(template.ParseFS(ui, "ui/swagger.html"))
+	redocTemplate   = template.Must(template.ParseFS(ui, "ui/redoc.html"))
+)
+
+// Assets are the script and the stylesheet loaded by a documentation page, with their subresource integrity
+// hashes, like "sha384-...". The browser refuses an asset not matching its hash, an empty hash is not checked.
+type Assets struct {
+	Script              string
+	ScriptIntegrity     string
+	Stylesheet          string
+	StylesheetIntegrity string
+}
+
+var (
+	// SwaggerUIAssets are the assets of the Swagger UI pages, an exact version of swagger-ui-dist.
+	// Set their integrity hashes, or point them to your own copy of the assets, before creating the pages.
+	SwaggerUIAssets = Assets{
+		Script:     "https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui-bundle.js",
+		Stylesheet: "https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui.css",
+	}
+
+	// RedocAssets are the assets of the Redoc pages, an exact version of redoc.
+	// Set their integrity hashes, or point them to your own copy of the assets, before creating the pages.
+	RedocAssets = Assets{
+		Script: "https://cdn.jsdelivr.net/npm/redoc@2.1.5/bundles/redoc.standalone.js",
+	}
+)
+
+// Handler returns a http handler serving the document in JSON.
+func (d *Document) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		marshal, err := json.Marshal(d)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		_, _ = w.Write(marshal)
+	})
+}
+
+// SwaggerUI returns a http handler serving a Swagger UI page for the document served at specURL, it loads the
+// SwaggerUIAssets.
+func SwaggerUI(title, specURL string) http.Handler {
+	return page(swaggerTemplate, title, specURL, SwaggerUIAssets)
+}
+
+// Redoc returns a http handler serving a Redoc page for the document served at specURL, it loads the RedocAssets.
+func Redoc(title, specURL string) http.Handler {
+	return page(redocTemplate, title, specURL, RedocAssets)
+}
+
+func page(t *template.Template, title, specURL string, assets Assets) http.Handler {
+	var buffer bytes.Buffer
+
+	err := t.Execute(&buffer, struct {
+		Title, SpecURL string
+		Assets         Assets
+	}{Title: title, SpecURL: specURL, Assets: assets})
+	if err != nil {
+		panic(err)
+	}
+
+	body := buffer.Bytes()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-type", "text/html; charset=utf-8")
+		_, _ = w.Write(body)
+	})
+}
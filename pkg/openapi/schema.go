@@ -0,0 +1,330 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexisvisco/kcd/internal/types"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	invalidComponentChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// schemas builds the schemas of go types and registers the structs as components.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// of returns the schema of the JSON encoding of t.
+func (s *schemas) of(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case implements(t, jsonMarshalerType):
+		return &Schema{}
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Array:
+		length := t.Len()
+		return &Schema{Type: "array", Items: s.of(t.Elem()), MinItems: &length, MaxItems: &length}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		return s.component(t)
+	case reflect.Interface:
+		return &Schema{}
+	}
+
+	return nativeSchema(t)
+}
+
+// component registers the struct t as a component and returns a reference to it.
+func (s *schemas) component(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return s.object(t, func(string) bool { return false }, "")
+	}
+
+	name, ok := s.names[t]
+	if !ok {
+		name = s.componentName(t)
+		s.names[t] = name
+
+		// the component is registered before its properties to handle recursive types.
+		s.components[name] = &Schema{}
+		*s.components[name] = *s.object(t, func(string) bool { return false }, "")
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *schemas) componentName(t reflect.Type) string {
+	base := invalidComponentChars.ReplaceAllString(t.Name(), "_")
+	name := base
+
+	for i := 2; ; i++ {
+		if _, exist := s.components[name]; !exist {
+			return name
+		}
+
+		name = base + strconv.Itoa(i)
+	}
+}
+
+// object returns the inline object schema of the struct t, skip tells if a go field path must be ignored.
+func (s *schemas) object(t reflect.Type, skip func(path string) bool, parent string) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	s.addProperties(schema, t, skip, parent)
+
+	return schema
+}
+
+func (s *schemas) addProperties(schema *Schema, t reflect.Type, skip func(path string) bool, parent string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		path := parent
+		if !field.Anonymous || name != "" {
+			path = joinPath(parent, field.Name)
+		}
+
+		if skip(path) {
+			continue
+		}
+
+		isStruct := fieldType.Kind() == reflect.Struct && !isScalar(fieldType)
+
+		// embedded structs without a json name have their fields promoted.
+		if field.Anonymous && name == "" && isStruct {
+			s.addProperties(schema, fieldType, skip, path)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		var property *Schema
+		if isStruct && hasSkippedPath(fieldType, skip, path) {
+			property = s.object(fieldType, skip, path)
+			if len(property.Properties) == 0 {
+				continue
+			}
+		} else {
+			property = s.of(field.Type)
+		}
+
+		if property.Ref == "" {
+			property.Description = field.Tag.Get("doc")
+			property.Example = example(field.Tag, fieldType)
+		}
+
+		schema.Properties[name] = property
+
+		if isRequired(field.Tag) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// hasSkippedPath returns true if one of the fields of the struct t is skipped.
+func hasSkippedPath(t reflect.Type, skip func(path string) bool, parent string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := joinPath(parent, field.Name)
+
+		if skip(path) {
+			return true
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct && !isScalar(fieldType) {
+			if field.Anonymous {
+				path = parent
+			}
+
+			if hasSkippedPath(fieldType, skip, path) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// parameter returns the schema of a parameter of type t.
+func parameter(t reflect.Type, multiple bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if multiple {
+		elem := t.Elem()
+		schema := &Schema{Type: "array", Items: parameter(elem, false)}
+
+		if t.Kind() == reflect.Array {
+			length := t.Len()
+			schema.MinItems, schema.MaxItems = &length, &length
+		}
+
+		return schema
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case types.IsCustomType(t) && t.ConvertibleTo(durationType):
+		return &Schema{Type: "string", Format: "duration", Example: "1m30s"}
+	case types.IsImplementingUnmarshaler(t):
+		return &Schema{Type: "string"}
+	}
+
+	return nativeSchema(t)
+}
+
+func nativeSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	}
+
+	return &Schema{}
+}
+
+// value converts the string s to a value matching the schema, used for defaults and examples.
+func value(schema *Schema, s string, exploder string) interface{} {
+	if schema.Type == "array" && schema.Items != nil {
+		if exploder == "" {
+			exploder = ","
+		}
+
+		parts := strings.Split(s, exploder)
+		values := make([]interface{}, 0, len(parts))
+
+		for _, part := range parts {
+			values = append(values, value(schema.Items, part, ""))
+		}
+
+		return values
+	}
+
+	switch schema.Type {
+	case "integer":
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+
+	return s
+}
+
+// example returns the value of the example tag for a field of type t.
+func example(tag reflect.StructTag, t reflect.Type) interface{} {
+	raw, ok := tag.Lookup("example")
+	if !ok {
+		return nil
+	}
+
+	return value(parameter(t, isMultiple(t)), raw, "")
+}
+
+// isRequired returns true if the key of the field is required by the required tag.
+func isRequired(tag reflect.StructTag) bool {
+	required, _ := strconv.ParseBool(tag.Get("required"))
+	return required
+}
+
+// jsonName returns the name of the field in the json encoding, ok is false if the field is not encoded.
+func jsonName(field reflect.StructField) (name string, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+		return "", false
+	}
+
+	return strings.Split(tag, ",")[0], true
+}
+
+// isScalar returns true if the struct t is encoded as a scalar value.
+func isScalar(t reflect.Type) bool {
+	return t == timeType || implements(t, jsonMarshalerType) || implements(t, textMarshalerType)
+}
+
+func isMultiple(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !types.IsImplementingUnmarshaler(t)
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
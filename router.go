@@ -0,0 +1,160 @@
+package kcd
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// Route is an endpoint registered on a Router.
+type Route struct {
+	Method   string
+	Pattern  string
+	Endpoint *Endpoint
+}
+
+// Router is a thin wrapper over a chi router which registers kcd handlers and records them.
+// The recorded routes are available with Routes.
+//
+// The methods of chi.Router that are not redefined are available and register handlers without recording them.
+type Router struct {
+	chi.Router
+
+	engine *Engine
+	prefix string
+	table  *routeTable
+}
+
+type routeTable struct {
+	routes []Route
+	mounts []mountedRouter
+}
+
+type mountedRouter struct {
+	prefix string
+	router *Router
+}
+
+// NewRouter returns a new Router using the configuration of the engine e.
+func NewRouter(e *Engine) *Router {
+	return &Router{Router: chi.NewRouter(), engine: e, table: &routeTable{}}
+}
+
+// Get registers a kcd handler for the GET method, see Engine.Handler for the handler.
+func (r *Router) Get(pattern string, h interface{}, defaultStatusCode int, options ...Option) {
+	r.Method(http.MethodGet, pattern, h, defaultStatusCode, options...)
+}
+
+// Head registers a kcd handler for the HEAD method, see Engine.Handler for the handler.
+func (r *Router) Head(pattern string, h interface{}, defaultStatusCode int, options ...Option) {
+	r.Method(http.MethodHead, pattern, h, defaultStatusCode, options...)
+}
+
+// Post registers a kcd handler for the POST method, see Engine.Handler for the handler.
+func (r *Router) Post(pattern string, h interface{}, defaultStatusCode int, options ...Option) {
+	r.Method(http.MethodPost, pattern, h, defaultStatusCode, options...)
+}
+
+// Put registers a kcd handler for the PUT method, see Engine.Handler for the handler.
+func (r *Router) Put(pattern string, h interface{}, defaultStatusCode int, options ...Option) {
+	r.Method(http.MethodPut, pattern, h, defaultStatusCode, options...)
+}
+
+// Patch registers a kcd handler for the PATCH method, see Engine.Handler for the handler.
+func (r *Router) Patch(pattern string, h interface{}, defaultStatusCode int, options ...Option) {
+	r.Method(http.MethodPatch, pattern, h, defaultStatusCode, options...)
+}
+
+// Delete registers a kcd handler for the DELETE method, see Engine.Handler for the handler.
+func (r *Router) Delete(pattern string, h interface{}, defaultStatusCode int, options ...Option) {
+	r.Method(http.MethodDelete, pattern, h, defaultStatusCode, options...)
+}
+
+// Options registers a kcd handler for the OPTIONS method, see Engine.Handler for the handler.
+func (r *Router) Options(pattern string, h interface{}, defaultStatusCode int, options ...Option) {
+	r.Method(http.MethodOptions, pattern, h, defaultStatusCode, options...)
+}
+
+// Method registers a kcd handler for the method, see Engine.Handler for the handler.
+func (r *Router) Method(method, pattern string, h interface{}, defaultStatusCode int, options ...Option) {
+	r.Endpoint(method, pattern, r.engine.Endpoint(h, defaultStatusCode, options...))
+}
+
+// Endpoint registers an endpoint for the method, it is useful for endpoints made with TypedEndpoint.
+func (r *Router) Endpoint(method, pattern string, endpoint *Endpoint) {
+	r.Router.Method(method, pattern, endpoint)
+
+	r.table.routes = append(r.table.routes, Route{
+		Method:   strings.ToUpper(method),
+		Pattern:  joinPattern(r.prefix, pattern),
+		Endpoint: endpoint,
+	})
+}
+
+// With returns a Router with inline middlewares which records its routes in r.
+func (r *Router) With(middlewares ...func(http.Handler) http.Handler) *Router {
+	return r.sub(r.Router.With(middlewares...), "")
+}
+
+// Group adds a new inline Router with a fresh middleware stack which records its routes in r.
+func (r *Router) Group(fn func(r *Router)) *Router {
+	sub := r.With()
+	if fn != nil {
+		fn(sub)
+	}
+
+	return sub
+}
+
+// Route mounts a sub Router along the pattern which records its routes in r.
+func (r *Router) Route(pattern string, fn func(r *Router)) *Router {
+	sub := r.sub(chi.NewRouter(), pattern)
+	if fn != nil {
+		fn(sub)
+	}
+
+	r.Router.Mount(pattern, sub.Router)
+
+	return sub
+}
+
+// Mount attaches another http.Handler along the pattern.
+// The routes of a mounted Router are part of the routes of r.
+func (r *Router) Mount(pattern string, h http.Handler) {
+	if router, ok := h.(*Router); ok {
+		r.table.mounts = append(r.table.mounts, mountedRouter{prefix: joinPattern(r.prefix, pattern), router: router})
+
+		// chi inherits some settings, like the not found handler, only from its own router type.
+		h = router.Router
+	}
+
+	r.Router.Mount(pattern, h)
+}
+
+// Routes returns the kcd routes registered on the router, its sub routers and its mounted routers.
+func (r *Router) Routes() []Route {
+	routes := make([]Route, 0, len(r.table.routes))
+	routes = append(routes, r.table.routes...)
+
+	for _, mount := range r.table.mounts {
+		for _, route := range mount.router.Routes() {
+			route.Pattern = joinPattern(mount.prefix, route.Pattern)
+			routes = append(routes, route)
+		}
+	}
+
+	return routes
+}
+
+func (r *Router) sub(router chi.Router, pattern string) *Router {
+	return &Router{Router: router, engine: r.engine, prefix: joinPattern(r.prefix, pattern), table: r.table}
+}
+
+func joinPattern(prefix, pattern string) string {
+	if prefix == "" {
+		return pattern
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(pattern, "/")
+}
@@ -0,0 +1,124 @@
+package kcd_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/go-chi/chi/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexisvisco/kcd"
+)
+
+type routerPagination struct {
+	Limit int `query:"limit" default:"10"`
+}
+
+type routerInput struct {
+	ID     string   `path:"id"`
+	Tags   []string `query:"tags" exploder:","`
+	Token  string   `header:"X-Token"`
+	UserID *int     `ctx:"user_id"`
+
+	Embedded
+
+	Pagination routerPagination
+}
+
+type routerOutput struct {
+	ID string `json:"id"`
+}
+
+func routerHandler(in *routerInput) (*routerOutput, error) {
+	return &routerOutput{ID: in.ID}, nil
+}
+
+func routerTypedHandler(_ context.Context, in *routerInput) (routerOutput, error) {
+	return routerOutput{ID: in.ID}, nil
+}
+
+func TestRouter(t *testing.T) {
+	r := kcd.NewRouter(kcd.New())
+	r.Use(middleware.RequestID)
+
+	r.Get("/health", std, http.StatusOK)
+	r.Route("/users", func(r *kcd.Router) {
+		r.Get("/{id}", routerHandler, http.StatusOK)
+		r.With(middleware.NoCache).Delete("/{id}", routerHandler, http.StatusNoContent)
+	})
+
+	admin := kcd.NewRouter(kcd.New())
+	admin.Endpoint(http.MethodPost, "/users/{id}", kcd.TypedEndpoint(kcd.New(), routerTypedHandler, http.StatusCreated))
+	r.Mount("/admin", admin)
+
+	routes := r.Routes()
+	require.Len(t, routes, 4)
+
+	t.Run("it should record the method and the pattern", func(t *testing.T) {
+		assert.Equal(t, http.MethodGet, routes[0].Method)
+		assert.Equal(t, "/health", routes[0].Pattern)
+		assert.Equal(t, http.MethodGet, routes[1].Method)
+		assert.Equal(t, "/users/{id}", routes[1].Pattern)
+		assert.Equal(t, http.MethodDelete, routes[2].Method)
+		assert.Equal(t, "/users/{id}", routes[2].Pattern)
+		assert.Equal(t, http.MethodPost, routes[3].Method)
+		assert.Equal(t, "/admin/users/{id}", routes[3].Pattern)
+	})
+
+	t.Run("it should record the handler", func(t *testing.T) {
+		assert.Equal(t, "github.com/alexisvisco/kcd_test.std", routes[0].Endpoint.Name)
+		assert.Nil(t, routes[0].Endpoint.Input)
+		assert.Nil(t, routes[0].Endpoint.Output)
+
+		endpoint := routes[1].Endpoint
+		assert.Equal(t, "github.com/alexisvisco/kcd_test.routerHandler", endpoint.Name)
+		assert.Equal(t, http.StatusOK, endpoint.StatusCode)
+		assert.Equal(t, reflect.TypeOf(routerInput{}), endpoint.Input)
+		assert.Equal(t, reflect.TypeOf(routerOutput{}), endpoint.Output)
+
+		typed := routes[3].Endpoint
+		assert.Equal(t, http.StatusCreated, typed.StatusCode)
+		assert.Equal(t, reflect.TypeOf(routerInput{}), typed.Input)
+		assert.Equal(t, reflect.TypeOf(routerOutput{}), typed.Output)
+	})
+
+	t.Run("it should record the fields", func(t *testing.T) {
+		fields := map[string]kcd.Field{}
+		for _, field := range routes[1].Endpoint.Fields {
+			fields[field.Name] = field
+		}
+
+		require.Len(t, fields, 6)
+
+		assert.Equal(t, map[string]string{"path": "id"}, fields["ID"].Sources)
+		assert.Equal(t, reflect.TypeOf(""), fields["ID"].Type)
+
+		assert.Equal(t, map[string]string{"query": "tags"}, fields["Tags"].Sources)
+		assert.Equal(t, ",", fields["Tags"].Exploder)
+		assert.True(t, fields["Tags"].Multiple)
+
+		assert.Equal(t, map[string]string{"header": "X-Token"}, fields["Token"].Sources)
+		assert.Equal(t, map[string]string{"ctx": "user_id"}, fields["UserID"].Sources)
+		assert.Equal(t, map[string]string{"query": "embedded_string"}, fields["EmbeddedString"].Sources)
+
+		assert.Equal(t, map[string]string{"query": "limit"}, fields["Pagination.Limit"].Sources)
+		assert.Equal(t, "10", fields["Pagination.Limit"].Default)
+	})
+
+	t.Run("it should serve the routes", func(t *testing.T) {
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		e := httpexpect.New(t, server.URL)
+
+		e.GET("/health").Expect().Status(http.StatusOK)
+		e.GET("/users/1").Expect().Status(http.StatusOK).JSON().Path("$.id").Equal("1")
+		e.DELETE("/users/1").Expect().Status(http.StatusNoContent).Header("Cache-Control").NotEmpty()
+		e.POST("/admin/users/2").Expect().Status(http.StatusCreated).JSON().Path("$.id").Equal("2")
+	})
+}